@@ -3,25 +3,22 @@ package card
 import "github.com/mikodream/mahjong/util"
 
 func HaveGang(tiles []ID) (ID, bool) {
-	cmap := NewCMap()
-	cmap.SetTiles(tiles)
-	for t, i := range cmap.GetTileMap() {
-		if i == 4 {
-			return t, true
-		}
+	if gangs := HaveGangs(tiles); len(gangs) > 0 {
+		return gangs[0], true
 	}
 	return 0, false
 }
 
+// HaveGangs 手里有四张的牌，从小到大
 func HaveGangs(tiles []ID) []ID {
-	cmap := NewCMap()
-	cmap.SetTiles(tiles)
+	counts := NewCounts(tiles)
 	gangs := make([]ID, 0)
-	for t, i := range cmap.GetTileMap() {
-		if i == 4 {
-			gangs = append(gangs, t)
+	counts.Each(func(id ID, n int) bool {
+		if n == 4 {
+			gangs = append(gangs, id)
 		}
-	}
+		return true
+	})
 	return gangs
 }
 
@@ -53,23 +50,20 @@ func CanChiTiles(cards []ID, card ID) [][]ID {
 
 // 判断是不是可以碰
 func CanPeng(cards []ID, card ID) bool {
-	cmap := NewCMap()
-	cmap.SetTiles(cards)
-	return cmap.GetTileCnt(card) == 2
+	counts := NewCounts(cards)
+	return counts.Count(card) == 2
 }
 
 // 判断是不是可以杠
 func CanGang(cards []ID, card ID) bool {
-	cmap := NewCMap()
-	cmap.SetTiles(cards)
-	return cmap.GetTileCnt(card) == 4
+	counts := NewCounts(cards)
+	return counts.Count(card) == 4
 }
 
 // 判断是不是可以明杠
 func CanMingGang(cards []ID, card ID) bool {
-	cmap := NewCMap()
-	cmap.SetTiles(cards)
-	return cmap.GetTileCnt(card) == 3
+	counts := NewCounts(cards)
+	return counts.Count(card) == 3
 }
 
 // IsSuit 是否普通牌
@@ -146,6 +140,11 @@ func IsTerminal(card ID) bool {
 	return IDInSlice(card, []ID{1, 9, 11, 19, 21, 29})
 }
 
+// IsBonus 是否花牌（春夏秋冬、梅兰竹菊）
+func IsBonus(card ID) bool {
+	return card >= MAHJONG_SEASON1
+}
+
 // IsYaoJiu IsTerminal 的别名
 func IsYaoJiu(card ID) bool {
 	return IsTerminal(card)
@@ -191,6 +190,10 @@ func (id ID) IsHonor() bool {
 	return id >= MAHJONG_EAST
 }
 
+func (id ID) IsBonus() bool {
+	return id >= MAHJONG_SEASON1
+}
+
 func (id ID) Rank() int {
 	return int(id) % 10
 }
@@ -111,6 +111,23 @@ var MahjongCards108 = []ID{
 	MAHJONG_BAM9, MAHJONG_BAM9, MAHJONG_BAM9, MAHJONG_BAM9,
 }
 
+// HonorTiles 字牌：东南西北、中发白
+var HonorTiles = []ID{
+	MAHJONG_EAST, MAHJONG_SOUTH, MAHJONG_WEST, MAHJONG_NORTH,
+	MAHJONG_RED, MAHJONG_GREE, MAHJONG_WHITE,
+}
+
+// MahjongCards136 136 张牌，万筒条加字牌
+var MahjongCards136 = append(append([]ID{}, MahjongCards108...),
+	repeat(HonorTiles, 4)...)
+
+// MahjongCards144 144 张牌，136 张加八张花牌
+var MahjongCards144 = append(append([]ID{}, MahjongCards136...), BonusTiles...)
+
+// MahjongCardsSanma 三人麻将 108 张牌：136 张去掉二万到八万
+var MahjongCardsSanma = append(append(repeat([]ID{MAHJONG_CRAK1, MAHJONG_CRAK9}, 4),
+	MahjongCards108[36:]...), repeat(HonorTiles, 4)...)
+
 // MahjongCards72 72 张牌
 var MahjongCards72 = []ID{
 	// 筒
@@ -150,20 +167,33 @@ var MahjongCards36 = []ID{
 	MAHJONG_CRAK9, MAHJONG_CRAK9, MAHJONG_CRAK9, MAHJONG_CRAK9,
 }
 
+// BonusTiles 花牌：春夏秋冬、梅兰竹菊各一张，不参与组牌
+var BonusTiles = []ID{
+	MAHJONG_SEASON1, MAHJONG_SEASON2, MAHJONG_SEASON3, MAHJONG_SEASON4,
+	MAHJONG_FLOWER1, MAHJONG_FLOWER2, MAHJONG_FLOWER3, MAHJONG_FLOWER4,
+}
+
 // AllTiles 所有种类的牌 (不含花牌/季节牌，共34种)
-// 用于遍历听牌
-var AllTiles = []ID{
-	// 万
-	MAHJONG_CRAK1, MAHJONG_CRAK2, MAHJONG_CRAK3, MAHJONG_CRAK4, MAHJONG_CRAK5,
-	MAHJONG_CRAK6, MAHJONG_CRAK7, MAHJONG_CRAK8, MAHJONG_CRAK9,
-	// 筒
-	MAHJONG_DOT1, MAHJONG_DOT2, MAHJONG_DOT3, MAHJONG_DOT4, MAHJONG_DOT5,
-	MAHJONG_DOT6, MAHJONG_DOT7, MAHJONG_DOT8, MAHJONG_DOT9,
-	// 条
-	MAHJONG_BAM1, MAHJONG_BAM2, MAHJONG_BAM3, MAHJONG_BAM4, MAHJONG_BAM5,
-	MAHJONG_BAM6, MAHJONG_BAM7, MAHJONG_BAM8, MAHJONG_BAM9,
-	// 风
-	MAHJONG_EAST, MAHJONG_SOUTH, MAHJONG_WEST, MAHJONG_NORTH,
-	// 箭 (中发白)
-	MAHJONG_RED, MAHJONG_GREE, MAHJONG_WHITE,
+// 用于遍历听牌。数牌按下标生成，顺序和原来一样：万、筒、条，东南西北，中发白；
+// 和下标的顺序（万、条、饼，东北南西，发中白）不一样，需要按下标顺序时用 FromIndex
+var AllTiles = func() []ID {
+	tiles := make([]ID, 0, TileKinds)
+	for _, first := range []ID{MAHJONG_CRAK1, MAHJONG_DOT1, MAHJONG_BAM1} {
+		for i := first.Index(); i < first.Index()+9; i++ {
+			tiles = append(tiles, FromIndex(i))
+		}
+	}
+	tiles = append(tiles, Winds...)
+	return append(tiles, MAHJONG_RED, MAHJONG_GREE, MAHJONG_WHITE)
+}()
+
+// repeat 每种牌重复 n 张
+func repeat(tiles []ID, n int) []ID {
+	ret := make([]ID, 0, len(tiles)*n)
+	for _, t := range tiles {
+		for i := 0; i < n; i++ {
+			ret = append(ret, t)
+		}
+	}
+	return ret
 }
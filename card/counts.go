@@ -0,0 +1,81 @@
+package card
+
+// Counts 每种牌的张数，按牌的下标（见 Index）排列，不含花牌
+// 是值类型，复制、比较都不用分配内存，用于需要反复统计手牌的地方，比如判断碰杠、胡牌、听牌
+type Counts [TileKinds]uint8
+
+// countIndexOf 牌在 Counts 中的下标，花牌和不存在的牌是 -1
+func countIndexOf(id ID) int {
+	if i := id.Index(); i < TileKinds {
+		return i
+	}
+	return -1
+}
+
+// NewCounts 统计牌的张数，花牌和不存在的牌不统计
+func NewCounts(tiles []ID) Counts {
+	var c Counts
+	for _, t := range tiles {
+		c.Add(t)
+	}
+	return c
+}
+
+// Add 加一张牌，花牌和不存在的牌返回 false
+func (c *Counts) Add(id ID) bool {
+	i := countIndexOf(id)
+	if i < 0 {
+		return false
+	}
+	c[i]++
+	return true
+}
+
+// Remove 去掉一张牌，没有这张牌时返回 false
+func (c *Counts) Remove(id ID) bool {
+	i := countIndexOf(id)
+	if i < 0 || c[i] == 0 {
+		return false
+	}
+	c[i]--
+	return true
+}
+
+// Count 某张牌的张数
+func (c *Counts) Count(id ID) int {
+	i := countIndexOf(id)
+	if i < 0 {
+		return 0
+	}
+	return int(c[i])
+}
+
+// Len 一共有几张牌
+func (c *Counts) Len() int {
+	n := 0
+	for _, v := range c {
+		n += int(v)
+	}
+	return n
+}
+
+// Each 按下标的顺序遍历张数不为 0 的牌，f 返回 false 时停止
+func (c *Counts) Each(f func(id ID, n int) bool) {
+	for i, v := range c {
+		if v > 0 && !f(FromIndex(i), int(v)) {
+			return
+		}
+	}
+}
+
+// Tiles 转成排好序的牌
+func (c *Counts) Tiles() []ID {
+	tiles := make([]ID, 0, c.Len())
+	c.Each(func(id ID, n int) bool {
+		for j := 0; j < n; j++ {
+			tiles = append(tiles, id)
+		}
+		return true
+	})
+	return tiles
+}
@@ -0,0 +1,57 @@
+package card
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCounts(t *testing.T) {
+	c := NewCounts([]ID{3, 1, 31, 43, 3, 29, 11, MAHJONG_FLOWER1})
+	if c.Len() != 7 || c.Count(3) != 2 || c.Count(MAHJONG_FLOWER1) != 0 || c.Count(10) != 0 {
+		t.Errorf("统计张数错误: %v", c)
+	}
+	if !reflect.DeepEqual(c.Tiles(), []ID{1, 3, 3, 11, 29, 31, 43}) {
+		t.Errorf("转成牌错误: %v", c.Tiles())
+	}
+	if !c.Remove(3) || c.Count(3) != 1 || c.Remove(4) || c.Add(MAHJONG_SEASON1) {
+		t.Errorf("增删牌错误: %v", c)
+	}
+	d := c
+	d.Add(5)
+	if c == d || c.Count(5) != 0 {
+		t.Errorf("Counts 应该是值类型")
+	}
+
+	var ids []ID
+	c.Each(func(id ID, n int) bool {
+		ids = append(ids, id)
+		return id < 11
+	})
+	if !reflect.DeepEqual(ids, []ID{1, 3, 11}) {
+		t.Errorf("遍历错误: %v", ids)
+	}
+	for _, id := range AllTiles {
+		var one Counts
+		if !one.Add(id) || one.Tiles()[0] != id {
+			t.Errorf("%d 的下标错误", id)
+		}
+	}
+}
+
+func TestHaveGangs(t *testing.T) {
+	tiles := []ID{31, 5, 31, 5, 31, 5, 31, 5, 7}
+	if gangs := HaveGangs(tiles); !reflect.DeepEqual(gangs, []ID{5, 31}) {
+		t.Errorf("HaveGangs 错误: %v", gangs)
+	}
+	if !CanPeng(tiles[:3], 31) || CanPeng(tiles, 31) || !CanGang(tiles, 5) || !CanMingGang(tiles[:6], 31) {
+		t.Errorf("判断碰杠错误")
+	}
+}
+
+func BenchmarkCanPeng(b *testing.B) {
+	tiles := []ID{1, 2, 3, 5, 5, 11, 12, 13, 21, 22, 23, 31, 31}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		CanPeng(tiles, 31)
+	}
+}
@@ -0,0 +1,76 @@
+package card
+
+// 牌的紧凑下标：ID 不连续（万 1-9、条 11-19、饼 21-29、风 31-34、箭 41-43、花 51-54 和 61-64），
+// 按 ID 从小到大依次编号，万 0-8、条 9-17、饼 18-26、风 27-30、箭 31-33、花 34-41，
+// 用于按下标存取的数组，比如 Counts
+const (
+	TileKinds  = 34                     // 不含花牌的牌的种类数
+	BonusKinds = 8                      // 花牌的种类数
+	IndexKinds = TileKinds + BonusKinds // 所有下标的个数
+	SuitKinds  = 27                     // 数牌的种类数，下标 0-26 是数牌
+)
+
+// indexIDs 下标 => ID
+var indexIDs = func() [IndexKinds]ID {
+	var ids [IndexKinds]ID
+	i := 0
+	for _, r := range [][2]ID{
+		{MAHJONG_CRAK1, MAHJONG_CRAK9}, {MAHJONG_BAM1, MAHJONG_BAM9}, {MAHJONG_DOT1, MAHJONG_DOT9},
+		{MAHJONG_EAST, MAHJONG_WEST}, {MAHJONG_GREE, MAHJONG_WHITE},
+		{MAHJONG_SEASON1, MAHJONG_SEASON4}, {MAHJONG_FLOWER1, MAHJONG_FLOWER4},
+	} {
+		for id := r[0]; id <= r[1]; id++ {
+			ids[i] = id
+			i++
+		}
+	}
+	return ids
+}()
+
+// idIndexes ID => 下标，不存在的牌是 -1
+var idIndexes = func() [MAHJONG_FLOWER4 + 1]int8 {
+	var index [MAHJONG_FLOWER4 + 1]int8
+	for i := range index {
+		index[i] = -1
+	}
+	for i, id := range indexIDs {
+		index[id] = int8(i)
+	}
+	return index
+}()
+
+// Index 牌的紧凑下标，不存在的牌返回 -1
+func (id ID) Index() int {
+	if id < 0 || int(id) >= len(idIndexes) {
+		return -1
+	}
+	return int(idIndexes[id])
+}
+
+// FromIndex 下标对应的牌，下标超出范围时返回 MAHJONG_PLACEHOLDER
+func FromIndex(i int) ID {
+	if i < 0 || i >= IndexKinds {
+		return MAHJONG_PLACEHOLDER
+	}
+	return indexIDs[i]
+}
+
+// Suit 牌的花色，和 tile 包的 WAN、TIAO、BING、FENG、DRAGON、SEASON、HUA 一样
+func (id ID) Suit() int {
+	return int(id) / 10
+}
+
+// IndexSuit 下标对应的牌的花色
+func IndexSuit(i int) int {
+	return FromIndex(i).Suit()
+}
+
+// IndexRank 下标对应的牌的点数，数牌是 1-9，字牌和花牌是在同类牌中的序号
+func IndexRank(i int) int {
+	return FromIndex(i).Rank()
+}
+
+// IsSuitIndex 是否数牌的下标，数牌点数不超过 7 时 i+1、i+2 是同一门花色的下两张
+func IsSuitIndex(i int) bool {
+	return i >= 0 && i < SuitKinds
+}
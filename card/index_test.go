@@ -0,0 +1,50 @@
+package card
+
+import "testing"
+
+func TestIndex(t *testing.T) {
+	for i := 0; i < IndexKinds; i++ {
+		id := FromIndex(i)
+		if id.Index() != i {
+			t.Errorf("下标 %d 对应 %v，%v 的下标是 %d", i, id, id, id.Index())
+		}
+		if i > 0 && id <= FromIndex(i-1) {
+			t.Errorf("下标 %d 对应的 %v 应该比前一个大", i, id)
+		}
+		if IsSuitIndex(i) != id.IsSuit() || (i < TileKinds) == id.IsBonus() {
+			t.Errorf("下标 %d 对应的 %v 类型错误", i, id)
+		}
+	}
+	for _, id := range []ID{0, 10, 20, 35, 44, 55, 65, -1, 100} {
+		if id.Index() != -1 {
+			t.Errorf("不存在的牌 %d 的下标应该是 -1，实际是 %d", id, id.Index())
+		}
+	}
+	if FromIndex(-1) != MAHJONG_PLACEHOLDER || FromIndex(IndexKinds) != MAHJONG_PLACEHOLDER {
+		t.Errorf("超出范围的下标应该返回 MAHJONG_PLACEHOLDER")
+	}
+	if MAHJONG_DOT9.Index() != SuitKinds-1 || MAHJONG_WHITE.Index() != TileKinds-1 || MAHJONG_FLOWER4.Index() != IndexKinds-1 {
+		t.Errorf("下标范围错误")
+	}
+	if IndexSuit(MAHJONG_BAM3.Index()) != 1 || IndexRank(MAHJONG_BAM3.Index()) != 3 || MAHJONG_RED.Suit() != 4 {
+		t.Errorf("花色、点数错误")
+	}
+}
+
+func TestAllTilesOrder(t *testing.T) {
+	// AllTiles 保持原来的顺序：万、筒、条、东南西北、中发白，和下标的顺序不一样
+	if AllTiles[9] != MAHJONG_DOT1 || AllTiles[18] != MAHJONG_BAM1 || AllTiles[30] != MAHJONG_NORTH || AllTiles[31] != MAHJONG_RED {
+		t.Errorf("AllTiles 的顺序变了: %v", AllTiles)
+	}
+	var seen [TileKinds]bool
+	for _, id := range AllTiles {
+		if i := id.Index(); i < 0 || i >= TileKinds || seen[i] {
+			t.Errorf("AllTiles 应该正好包含每种牌一次: %v", AllTiles)
+		} else {
+			seen[i] = true
+		}
+	}
+	if len(AllTiles) != TileKinds {
+		t.Errorf("AllTiles 应该有 %d 种牌, 实际 %d", TileKinds, len(AllTiles))
+	}
+}
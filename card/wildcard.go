@@ -0,0 +1,123 @@
+package card
+
+// Wildcards 赖子（百搭牌），手里的赖子可以当作任意一张牌
+// 可以是固定的牌（比如红中赖子），也可以开局翻一张牌，用 NextTile 决定
+type Wildcards []ID
+
+// Contains 是否赖子
+func (w Wildcards) Contains(id ID) bool {
+	return IDInSlice(id, w)
+}
+
+// Split 把牌分成普通牌和赖子，返回普通牌和赖子的张数
+func (w Wildcards) Split(tiles []ID) ([]ID, int) {
+	if len(w) == 0 {
+		return tiles, 0
+	}
+	normal := make([]ID, 0, len(tiles))
+	for _, t := range tiles {
+		if !w.Contains(t) {
+			normal = append(normal, t)
+		}
+	}
+	return normal, len(tiles) - len(normal)
+}
+
+// 字牌的顺序，ID 的大小不是这个顺序，找下一张牌时要用这里的顺序
+var (
+	Winds   = []ID{MAHJONG_EAST, MAHJONG_SOUTH, MAHJONG_WEST, MAHJONG_NORTH} // 东南西北
+	Dragons = []ID{MAHJONG_WHITE, MAHJONG_GREE, MAHJONG_RED}                 // 白发中
+)
+
+// NextTile 翻出的牌的下一张，翻牌定赖子、日本麻将的宝牌指示牌都用它
+// 万条饼 9 的下一张是 1，风牌按东南西北循环，箭牌按白发中循环，花牌没有下一张
+func NextTile(id ID) ID {
+	if id.IsSuit() {
+		if id.Rank() == 9 {
+			return id - 8
+		}
+		return id + 1
+	}
+	for _, cycle := range [][]ID{Winds, Dragons} {
+		for i, t := range cycle {
+			if t == id {
+				return cycle[(i+1)%len(cycle)]
+			}
+		}
+	}
+	return MAHJONG_PLACEHOLDER
+}
+
+// CanPengWith 判断是不是可以碰，手里的赖子可以补齐
+// 打出的牌是赖子时不能碰
+func CanPengWith(cards []ID, card ID, wildcards Wildcards) bool {
+	if wildcards.Contains(card) {
+		return false
+	}
+	normal, jokers := wildcards.Split(cards)
+	counts := NewCounts(normal)
+	return counts.Count(card)+jokers >= 2
+}
+
+// CanMingGangWith 判断是不是可以明杠，手里的赖子可以补齐
+// 打出的牌是赖子时不能杠
+func CanMingGangWith(cards []ID, card ID, wildcards Wildcards) bool {
+	if wildcards.Contains(card) {
+		return false
+	}
+	normal, jokers := wildcards.Split(cards)
+	counts := NewCounts(normal)
+	return counts.Count(card)+jokers >= 3
+}
+
+// CanChiWith 判断是不是可以吃，手里的赖子可以补齐
+func CanChiWith(cards []ID, card ID, wildcards Wildcards) bool {
+	if card == 0 {
+		return false
+	}
+	return len(CanChiTilesWith(cards, card, wildcards)) > 0
+}
+
+// CanChiTilesWith 可以吃的牌，缺的牌用手里的赖子补齐，返回的是手里实际要拿出的牌
+// 打出的牌是赖子时不能吃
+func CanChiTilesWith(cards []ID, card ID, wildcards Wildcards) [][]ID {
+	if !card.IsSuit() || wildcards.Contains(card) {
+		return [][]ID{}
+	}
+	normal := make([]ID, 0, len(cards))
+	jokers := make([]ID, 0)
+	for _, t := range cards {
+		if wildcards.Contains(t) {
+			jokers = append(jokers, t)
+		} else {
+			normal = append(normal, t)
+		}
+	}
+	ret := make([][]ID, 0)
+	for _, pair := range [][2]ID{{card + 1, card + 2}, {card - 1, card - 2}, {card - 1, card + 1}} {
+		if !sameSuit(card, pair[0]) || !sameSuit(card, pair[1]) {
+			continue
+		}
+		tiles := make([]ID, 0, 2)
+		need := 0
+		for _, t := range pair {
+			if IDInSlice(t, normal) {
+				tiles = append(tiles, t)
+			} else if need < len(jokers) {
+				tiles = append(tiles, jokers[need])
+				need++
+			} else {
+				need++
+			}
+		}
+		if need <= len(jokers) {
+			ret = append(ret, tiles)
+		}
+	}
+	return ret
+}
+
+// sameSuit 是否同一门花色的序数牌
+func sameSuit(a, b ID) bool {
+	return a.IsSuit() && b.IsSuit() && a > 0 && b > 0 && a.Rank() != 0 && b.Rank() != 0 && a/10 == b/10
+}
@@ -0,0 +1,54 @@
+package card
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNextTile(t *testing.T) {
+	cases := map[ID]ID{
+		MAHJONG_CRAK1:   MAHJONG_CRAK2,
+		MAHJONG_CRAK9:   MAHJONG_CRAK1,
+		MAHJONG_DOT9:    MAHJONG_DOT1,
+		MAHJONG_EAST:    MAHJONG_SOUTH,
+		MAHJONG_WEST:    MAHJONG_NORTH,
+		MAHJONG_NORTH:   MAHJONG_EAST,
+		MAHJONG_WHITE:   MAHJONG_GREE,
+		MAHJONG_GREE:    MAHJONG_RED,
+		MAHJONG_RED:     MAHJONG_WHITE,
+		MAHJONG_SEASON1: MAHJONG_PLACEHOLDER,
+	}
+	for indicator, expected := range cases {
+		if next := NextTile(indicator); next != expected {
+			t.Errorf("NextTile(%d) 期望 %d, 实际 %d", indicator, expected, next)
+		}
+	}
+}
+
+func TestCanPengChiWithWildcards(t *testing.T) {
+	wildcards := Wildcards{MAHJONG_RED}
+	if !CanPengWith([]ID{5, 42, 11}, 5, wildcards) {
+		t.Error("一张 5 加一个赖子应该可以碰")
+	}
+	if CanPengWith([]ID{5, 5, 11}, 42, wildcards) {
+		t.Error("打出的赖子不能碰")
+	}
+	if CanPengWith([]ID{5, 11, 12}, 5, wildcards) {
+		t.Error("一张 5 没有赖子不能碰")
+	}
+	if !CanMingGangWith([]ID{5, 5, 42}, 5, wildcards) || CanMingGangWith([]ID{5, 42, 11}, 5, wildcards) {
+		t.Error("两张 5 加一个赖子才能明杠")
+	}
+
+	chis := CanChiTilesWith([]ID{6, 42}, 5, wildcards)
+	expected := [][]ID{{6, 42}, {42, 6}}
+	if !reflect.DeepEqual(chis, expected) {
+		t.Errorf("CanChiTilesWith 期望 %v, 实际 %v", expected, chis)
+	}
+	if CanChiWith([]ID{42}, 9, wildcards) {
+		t.Error("一个赖子不能吃")
+	}
+	if CanChiWith([]ID{11, 42}, 9, wildcards) {
+		t.Error("不同花色不能吃")
+	}
+}
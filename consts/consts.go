@@ -8,12 +8,16 @@ const (
 	PENG
 	GANG
 	WIN
+	AN_GANG // 暗杠，自己回合的操作
+	BU_GANG // 补杠，自己回合的操作
 )
 const PlayMahjongTimeout = 30 * time.Second
 
 var OpCodeData = map[int]string{
-	CHI:  "吃",
-	PENG: "碰",
-	GANG: "杠",
-	WIN:  "胡",
+	CHI:     "吃",
+	PENG:    "碰",
+	GANG:    "杠",
+	WIN:     "胡",
+	AN_GANG: "暗杠",
+	BU_GANG: "补杠",
 }
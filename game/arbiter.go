@@ -0,0 +1,183 @@
+package game
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+	"github.com/mikodream/mahjong/util"
+)
+
+// ErrInvalidClaim 玩家提交的吃碰杠胡不合法
+var ErrInvalidClaim = errors.New("game: invalid claim")
+
+// Claim 玩家对一张打出的牌的操作
+type Claim struct {
+	Player *PlayerController
+	Op     int       // consts.CHI / PENG / GANG / WIN
+	Tiles  []card.ID // 组成明牌的牌，包含打出的那张
+}
+
+// Arbiter 多个玩家同时要一张牌时，决定谁的操作生效
+type Arbiter struct {
+	MultiRon bool // 是否允许一炮多响
+}
+
+// claimPriority 操作的优先级：胡 > 杠、碰 > 吃
+func claimPriority(op int) int {
+	switch op {
+	case consts.WIN:
+		return 3
+	case consts.GANG, consts.PENG:
+		return 2
+	case consts.CHI:
+		return 1
+	}
+	return 0
+}
+
+// Resolve 从所有玩家的操作中选出生效的操作
+// claims 需要按出牌者下家开始的座位顺序排列，同优先级时离出牌者近的玩家优先
+// 允许一炮多响时，所有胡牌的操作同时生效，否则只有一个操作生效
+func (a Arbiter) Resolve(claims []Claim) []Claim {
+	var best *Claim
+	for i := range claims {
+		if best == nil || claimPriority(claims[i].Op) > claimPriority(best.Op) {
+			best = &claims[i]
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	if best.Op != consts.WIN || !a.MultiRon {
+		return []Claim{*best}
+	}
+	wins := make([]Claim, 0, len(claims))
+	for _, c := range claims {
+		if c.Op == consts.WIN {
+			wins = append(wins, c)
+		}
+	}
+	return wins
+}
+
+// claim 收集所有玩家对打出的牌的操作，仲裁后执行生效的操作
+// 没有人要的时候返回空
+func (g *Game) claim(discarder *PlayerController, tile card.ID) ([]Claim, error) {
+	claims, err := g.collectClaims(discarder, tile)
+	if err != nil {
+		return nil, err
+	}
+	winning := g.arbiter.Resolve(claims)
+	if len(winning) == 0 {
+		return nil, nil
+	}
+	for _, c := range winning {
+		c.Player.Claim(c.Op, c.Tiles, g.pile)
+	}
+	g.pile.BottomDrawOne()
+	if winning[0].Op != consts.WIN {
+		g.players.SetCurrent(winning[0].Player.ID())
+		g.pile.SetCurrentPlayer(winning[0].Player)
+	}
+	return winning, nil
+}
+
+// collectClaims 询问所有可以操作的玩家
+// 每个玩家只看到自己的手牌和自己能做的操作，不会知道其他玩家的选择
+func (g *Game) collectClaims(discarder *PlayerController, tile card.ID) ([]Claim, error) {
+	state := g.ExtractState(g.peek())
+	canWin := make(map[int]bool, len(state.CanWin))
+	for _, p := range state.CanWin {
+		canWin[p.ID()] = true
+	}
+	claims := make([]Claim, 0, len(state.PlayerSequence))
+	for _, player := range state.PlayerSequence {
+		privileges := state.SpecialPrivileges[player.ID()]
+		if player.ID() == discarder.ID() || (!canWin[player.ID()] && len(privileges) == 0) {
+			continue
+		}
+		op, tiles, err := player.respond(g.responderState(player, discarder, tile, privileges, canWin[player.ID()]), tile)
+		if err != nil {
+			return nil, err
+		}
+		if len(tiles) == 0 {
+			g.pile.AddSayNoPlayer(player)
+			continue
+		}
+		if op == consts.WIN {
+			if !canWin[player.ID()] {
+				return nil, ErrInvalidClaim
+			}
+		} else if !util.IntInSlice(op, privileges) || !isValidMeld(op, tile, player.Hand(), tiles, g.meldWildcards()) {
+			return nil, ErrInvalidClaim
+		}
+		claims = append(claims, Claim{Player: player, Op: op, Tiles: tiles})
+	}
+	return claims, nil
+}
+
+// responderState 询问玩家要不要别人打出的牌时给它看的状态
+// 手牌是它自己的，SpecialPrivileges 和 CanWin 里也只有它自己
+func (g *Game) responderState(player, discarder *PlayerController, tile card.ID, ops []int, canWin bool) State {
+	state := g.ExtractState(player)
+	state.LastPlayer = discarder
+	state.LastPlayedTile = tile
+	state.SpecialPrivileges = make(map[int][]int, 1)
+	if len(ops) > 0 {
+		state.SpecialPrivileges[player.ID()] = ops
+	}
+	state.CanWin = nil
+	if canWin {
+		state.CanWin = []*PlayerController{player}
+	}
+	return state
+}
+
+// isValidMeld 判断吃碰杠的牌是否合法
+// tiles 必须包含打出的牌 tile，其余的牌必须都在手牌中，其中的赖子 wildcards 可以补齐缺的牌
+func isValidMeld(op int, tile card.ID, hand, tiles []card.ID, wildcards card.Wildcards) bool {
+	rest := make([]card.ID, len(tiles))
+	copy(rest, tiles)
+	rest = sliceDel(rest, tile)
+	if len(rest) != len(tiles)-1 || wildcards.Contains(tile) {
+		return false
+	}
+	handCopy := make([]card.ID, len(hand))
+	copy(handCopy, hand)
+	for _, t := range rest {
+		if !card.IDInSlice(t, handCopy) {
+			return false
+		}
+		handCopy = sliceDel(handCopy, t)
+	}
+	normal, _ := wildcards.Split(rest)
+
+	switch op {
+	case consts.CHI:
+		if len(tiles) != 3 || !tile.IsSuit() {
+			return false
+		}
+		// 除了赖子以外的牌同一门花色、各不相同，并且在一个顺子的范围内
+		seq := append([]card.ID{tile}, normal...)
+		sort.Slice(seq, func(i, j int) bool { return seq[i] < seq[j] })
+		for i, t := range seq {
+			if !t.IsSuit() || t/10 != tile/10 || i > 0 && t == seq[i-1] {
+				return false
+			}
+		}
+		return seq[len(seq)-1]-seq[0] <= 2
+	case consts.PENG, consts.GANG:
+		if op == consts.PENG && len(tiles) != 3 || op == consts.GANG && len(tiles) != 4 {
+			return false
+		}
+		for _, t := range normal {
+			if t != tile {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
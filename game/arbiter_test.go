@@ -0,0 +1,109 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+)
+
+func TestArbiterResolve(t *testing.T) {
+	players := make([]*PlayerController, 0, 3)
+	for _, p := range newTestPlayers(3) {
+		players = append(players, NewPlayerController(p))
+	}
+	chi := Claim{Player: players[0], Op: consts.CHI, Tiles: []card.ID{1, 2, 3}}
+	peng := Claim{Player: players[1], Op: consts.PENG, Tiles: []card.ID{3, 3, 3}}
+	win1 := Claim{Player: players[1], Op: consts.WIN, Tiles: []card.ID{3}}
+	win2 := Claim{Player: players[2], Op: consts.WIN, Tiles: []card.ID{3}}
+
+	if got := (Arbiter{}).Resolve(nil); len(got) != 0 {
+		t.Errorf("没有操作时不应有结果: %v", got)
+	}
+	if got := (Arbiter{}).Resolve([]Claim{chi, peng}); len(got) != 1 || got[0].Op != consts.PENG {
+		t.Errorf("碰应该优先于吃: %v", got)
+	}
+	if got := (Arbiter{}).Resolve([]Claim{chi, win2, win1}); len(got) != 1 || got[0].Player != players[2] {
+		t.Errorf("同时胡牌时离出牌者近的优先: %v", got)
+	}
+	if got := (Arbiter{MultiRon: true}).Resolve([]Claim{chi, win1, peng, win2}); len(got) != 2 ||
+		got[0].Player != players[1] || got[1].Player != players[2] {
+		t.Errorf("一炮多响应该都胡: %v", got)
+	}
+}
+
+func TestIsValidMeld(t *testing.T) {
+	hand := []card.ID{1, 2, 3, 3, 3, 5}
+	if !isValidMeld(consts.CHI, 4, hand, []card.ID{2, 3, 4}, nil) {
+		t.Error("吃 234 验证失败")
+	}
+	if isValidMeld(consts.CHI, 4, hand, []card.ID{4, 5, 6}, nil) {
+		t.Error("手里没有 6 不能吃")
+	}
+	if !isValidMeld(consts.PENG, 3, hand, []card.ID{3, 3, 3}, nil) {
+		t.Error("碰 3 验证失败")
+	}
+	if !isValidMeld(consts.GANG, 3, hand, []card.ID{3, 3, 3, 3}, nil) {
+		t.Error("杠 3 验证失败")
+	}
+	if isValidMeld(consts.PENG, 5, hand, []card.ID{5, 5, 5}, nil) {
+		t.Error("只有一张 5 不能碰")
+	}
+
+	// 红中是赖子时可以补齐缺的牌
+	wildcards := card.Wildcards{card.MAHJONG_RED}
+	hand = []card.ID{1, 5, card.MAHJONG_RED, card.MAHJONG_RED}
+	if !isValidMeld(consts.CHI, 4, hand, []card.ID{card.MAHJONG_RED, 4, 5}, wildcards) {
+		t.Error("赖子加 5 吃 4 验证失败")
+	}
+	if isValidMeld(consts.CHI, 4, hand, []card.ID{1, card.MAHJONG_RED, 4}, wildcards) {
+		t.Error("1 和 4 不在一个顺子里")
+	}
+	if !isValidMeld(consts.GANG, 5, hand, []card.ID{5, 5, card.MAHJONG_RED, card.MAHJONG_RED}, wildcards) {
+		t.Error("一张 5 加两个赖子杠 5 验证失败")
+	}
+	if isValidMeld(consts.PENG, 5, hand, []card.ID{5, card.MAHJONG_RED, card.MAHJONG_RED}, nil) {
+		t.Error("不能用赖子碰时红中就是红中")
+	}
+}
+
+// seenPlayer 记录被询问要不要牌时看到的状态，什么都不要
+type seenPlayer struct {
+	*testPlayer
+	seen []State
+}
+
+func (p *seenPlayer) Take(tiles []card.ID, gameState State) (int, []card.ID, error) {
+	p.seen = append(p.seen, gameState)
+	return 0, nil, nil
+}
+
+func TestClaimState(t *testing.T) {
+	next := &seenPlayer{testPlayer: &testPlayer{id: 1}}
+	across := &seenPlayer{testPlayer: &testPlayer{id: 2}}
+	g := New([]Player{&testPlayer{id: 0}, next, across})
+	discarder := g.players.GetPlayerController(0)
+	g.players.GetPlayerController(1).AddTiles([]card.ID{4, 6, 11, 12})
+	g.players.GetPlayerController(2).AddTiles([]card.ID{5, 5, 21, 22})
+	g.players.SetCurrent(discarder.ID())
+	g.pile.AddDiscard(discarder, 5)
+	g.pile.SetLastPlayer(discarder)
+	g.pile.SetOriginallyPlayer(g.peek())
+
+	if _, err := g.collectClaims(discarder, 5); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []*seenPlayer{next, across} {
+		if len(p.seen) != 1 {
+			t.Fatalf("%d 应该被询问一次, 实际 %d 次", p.id, len(p.seen))
+		}
+		s := p.seen[0]
+		if s.CurrentPlayer.ID() != p.id || !reflect.DeepEqual(s.CurrentPlayerHand, g.players.GetPlayerController(p.id).Tiles()) {
+			t.Errorf("%d 看到的应该是自己的手牌: %v", p.id, s.CurrentPlayerHand)
+		}
+		if len(s.SpecialPrivileges) != 1 || len(s.SpecialPrivileges[p.id]) == 0 || s.LastPlayedTile != 5 {
+			t.Errorf("%d 只应该看到自己能做的操作: %v", p.id, s.SpecialPrivileges)
+		}
+	}
+}
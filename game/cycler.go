@@ -40,3 +40,23 @@ func (c *Cycler) Next() int {
 	c.current = (c.current + c.direction + elementCount) % elementCount
 	return c.elements[c.current]
 }
+
+// Peek 返回下一个元素，但不移动游标
+func (c *Cycler) Peek() int {
+	c.Mutex.Lock()
+	defer c.Mutex.Unlock()
+	elementCount := len(c.elements)
+	return c.elements[(c.current+c.direction+elementCount)%elementCount]
+}
+
+// SetCurrent 将游标移动到指定元素上
+func (c *Cycler) SetCurrent(element int) {
+	c.Mutex.Lock()
+	defer c.Mutex.Unlock()
+	for i, e := range c.elements {
+		if e == element {
+			c.current = i
+			return
+		}
+	}
+}
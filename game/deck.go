@@ -2,36 +2,178 @@ package game
 
 import (
 	"math/rand"
+	"time"
 
 	"github.com/mikodream/mahjong/card"
-	"github.com/mikodream/mahjong/tile"
 )
 
+// 实体牌墙：洗好的牌按顺序码成四面牌墙，每墩上下两张，
+// 第 0 面在庄家面前，按出牌顺序依次是下家、对家、上家面前的牌墙
+const (
+	wallSides  = 4 // 四面牌墙
+	stackSize  = 2 // 每墩两张
+	dealBlock  = 4 // 起手每次抓一墩两墩，也就是四张
+	diceFaces  = 6
+	diceNumber = 2
+)
+
+// redTile 赤牌的标记，比如日本麻将的赤 5
+// 只在牌墙、手牌、牌池里面的实体牌上标记，这样赤牌在谁手里、打到了哪里都跟着牌走；
+// 对外返回的牌都去掉了标记，和普通的牌一样
+const redTile card.ID = 1 << 16
+
+// RedTile 带赤牌标记的牌，Player.Play 返回 RedTile(tile) 表示手里有普通的 tile 时也打出赤牌
+func RedTile(tile card.ID) card.ID {
+	return tile | redTile
+}
+
+// plainTile 去掉赤牌标记
+func plainTile(t card.ID) card.ID {
+	return t &^ redTile
+}
+
+// plainTiles 去掉赤牌标记的副本
+func plainTiles(tiles []card.ID) []card.ID {
+	plain := make([]card.ID, len(tiles))
+	for i, t := range tiles {
+		plain[i] = plainTile(t)
+	}
+	return plain
+}
+
 type Deck struct {
-	tiles []card.ID
+	tiles   []card.ID // 牌头在前，牌尾在后
+	dead    []card.ID // 王牌（岭上牌），从牌尾往牌头的顺序，补牌摸走的位置保留
+	rinshan int       // 从王牌补牌的张数
+	dice    [diceNumber]int
+	breakAt Breakpoint
+	wall    []card.ID // 开牌时的牌墙，用于核对洗牌
+
+	rng    *rand.Rand
+	seed   int64
+	preset bool      // 按指定的顺序码牌，不洗牌，开牌时也不重新排列
+	reds   []card.ID // 这些牌各有一张是赤牌
+}
+
+// DeckOption 创建牌墙时的可选配置
+type DeckOption func(d *Deck)
+
+// DeckSeed 用固定的种子洗牌、掷骰子，种子一样时整局牌可以重现
+func DeckSeed(seed int64) DeckOption {
+	return func(d *Deck) {
+		d.seed = seed
+		d.rng = rand.New(rand.NewSource(seed))
+	}
+}
+
+// DeckRand 用调用方提供的随机数洗牌、掷骰子
+func DeckRand(rng *rand.Rand) DeckOption {
+	return func(d *Deck) {
+		d.seed = 0
+		d.rng = rng
+	}
 }
 
-func NewDeck() *Deck {
+// DeckWall 按 tiles 的顺序组成牌墙，不洗牌，tiles 是开牌之后从牌头到牌尾的顺序
+// 用于测试和回放，这时创建牌墙的 tiles 参数不起作用
+func DeckWall(tiles []card.ID) DeckOption {
+	return func(d *Deck) {
+		d.tiles = make([]card.ID, len(tiles))
+		copy(d.tiles, tiles)
+		d.preset = true
+	}
+}
+
+// DeckRedTiles 牌墙里 tiles 中的每种牌各有一张是赤牌，洗牌之前标记，所以赤牌在牌墙里的位置也是洗牌决定的
+// 用 DeckWall 指定牌墙时，标记每种牌在牌头方向的第一张
+func DeckRedTiles(tiles ...card.ID) DeckOption {
+	return func(d *Deck) {
+		d.reds = append(d.reds, tiles...)
+	}
+}
+
+// Breakpoint 开牌的位置：第几面牌墙（0 是庄家面前），从这面牌墙的右端数第几墩
+type Breakpoint struct {
+	Side  int
+	Stack int
+}
+
+func NewDeck(opts ...DeckOption) *Deck {
+	return NewDeckWithTiles(card.MahjongCards136, opts...)
+}
+
+// NewDeckWithTiles 用指定的牌洗牌组成牌墙
+// 没有指定种子或者随机数时用当前时间作为种子，可以通过 Seed 取出来记录下来
+func NewDeckWithTiles(tiles []card.ID, opts ...DeckOption) *Deck {
 	deck := &Deck{}
-	fillDeck(deck)
+	DeckSeed(time.Now().UnixNano())(deck)
+	for _, opt := range opts {
+		opt(deck)
+	}
+	if deck.preset {
+		markRed(deck.tiles, deck.reds)
+	} else {
+		fillDeck(deck, tiles)
+	}
 	return deck
 }
 
+// Seed 洗牌用的种子，用 DeckRand 提供随机数时是 0
+func (d *Deck) Seed() int64 {
+	return d.seed
+}
+
+// Rand 牌墙用的随机数，骰子之类需要随机的地方都从这里取，这样整局牌可以重现
+func (d *Deck) Rand() *rand.Rand {
+	return d.rng
+}
+
 func (d *Deck) NoTiles() bool {
 	return len(d.tiles) == 0
 }
 
+// Len 牌墙里剩下的张数，不包括王牌
+func (d *Deck) Len() int {
+	return len(d.tiles)
+}
+
+// Stacks 牌墙里剩下的墩数，不包括王牌
+func (d *Deck) Stacks() int {
+	return (len(d.tiles) + stackSize - 1) / stackSize
+}
+
 func (d *Deck) DrawOne() card.ID {
 	return d.Draw(1)[0]
 }
 
 func (d *Deck) Draw(amount int) []card.ID {
+	return plainTiles(d.draw(amount))
+}
+
+// draw 从牌头摸牌，返回带赤牌标记的实体牌
+func (d *Deck) draw(amount int) []card.ID {
 	tiles := d.tiles[0:amount]
 	d.tiles = d.tiles[amount:]
 	return tiles
 }
 
+// BottomDrawOne 从牌尾摸一张牌
+// 留了王牌时从王牌补牌，再把牌墙的最后一张移到王牌里，所以王牌的张数不变
 func (d *Deck) BottomDrawOne() card.ID {
+	return plainTile(d.bottomDraw())
+}
+
+// bottomDraw 从牌尾摸一张牌，返回带赤牌标记的实体牌
+func (d *Deck) bottomDraw() card.ID {
+	if d.rinshan < len(d.dead) {
+		tile := d.dead[d.rinshan]
+		d.rinshan++
+		if len(d.tiles) > 0 {
+			d.dead = append(d.dead, d.tiles[len(d.tiles)-1])
+			d.tiles = d.tiles[:len(d.tiles)-1]
+		}
+		return tile
+	}
 	tile := d.tiles[len(d.tiles)-1]
 	d.tiles = d.tiles[:len(d.tiles)-1]
 	return tile
@@ -42,31 +184,95 @@ func (d *Deck) SetNextDraw(tile card.ID) {
 	d.tiles = append([]card.ID{tile}, d.tiles...)
 }
 
-func fillDeck(deck *Deck) {
-	tiles := make([]int, 0, 144)
-	generate := func(tile, num, count int) []int {
-		ret := make([]int, 0, num*count)
-		for i := 0; i < count; i++ {
-			for j := 1; j <= num; j++ {
-				ret = append(ret, tile*10+j)
-			}
+// Break 掷两颗骰子开牌
+// 点数之和从庄家开始按出牌顺序数到第几面牌墙，再从这面牌墙的右端数同样的墩数，
+// 数过的墩留在牌尾，往左的下一墩是牌头；用 DeckWall 指定了顺序的牌墙只记录骰子
+func (d *Deck) Break(dice1, dice2 int) Breakpoint {
+	sum := dice1 + dice2
+	d.dice = [diceNumber]int{dice1, dice2}
+	d.breakAt = Breakpoint{Side: (sum - 1) % wallSides, Stack: sum}
+	if !d.preset {
+		stacks := d.Stacks()
+		start := (wallStart(d.breakAt.Side, stacks) + sum) % stacks * stackSize
+		if start > len(d.tiles) {
+			start = len(d.tiles)
 		}
-		return ret
+		d.tiles = append(d.tiles[start:len(d.tiles):len(d.tiles)], d.tiles[:start]...)
+	}
+	d.wall = make([]card.ID, len(d.tiles))
+	copy(d.wall, d.tiles)
+	return d.breakAt
+}
+
+// Wall 开牌时的牌墙，从牌头到牌尾，还没开牌时是空的
+func (d *Deck) Wall() []card.ID {
+	return plainTiles(d.wall)
+}
+
+// Dice 开牌时掷的骰子，还没开牌时是 0
+func (d *Deck) Dice() (int, int) {
+	return d.dice[0], d.dice[1]
+}
+
+// Breakpoint 开牌的位置
+func (d *Deck) Breakpoint() Breakpoint {
+	return d.breakAt
+}
+
+// SetDeadWall 把牌尾的 n 张留作王牌，杠后补牌从王牌摸
+func (d *Deck) SetDeadWall(n int) {
+	if n > len(d.tiles) {
+		n = len(d.tiles)
+	}
+	d.dead = make([]card.ID, 0, n)
+	for i := 0; i < n; i++ {
+		d.dead = append(d.dead, d.tiles[len(d.tiles)-1-i])
 	}
-	tiles = append(tiles, generate(tile.WAN, 9, 4)...)
-	tiles = append(tiles, generate(tile.TIAO, 9, 4)...)
-	tiles = append(tiles, generate(tile.BING, 9, 4)...)
-	tiles = append(tiles, generate(tile.FENG, 4, 4)...)
-	tiles = append(tiles, generate(tile.DRAGON, 3, 4)...)
-	// tiles = append(tiles, generate(tile.SEASON, 4, 1)...)
-	// tiles = append(tiles, generate(tile.HUA, 4, 1)...)
-	// tiles = append(tiles, generate(tile.HUA, 4, 1)...)
-	shuffleCards(tiles)
-	for _, t := range tiles {
-		deck.tiles = append(deck.tiles, card.ID(t))
+	d.tiles = d.tiles[:len(d.tiles)-n]
+	d.rinshan = 0
+}
+
+// DeadWall 王牌，从牌尾往牌头的顺序
+// 前 Replacements() 张已经补牌摸走了，补牌时从牌墙移过来的牌接在最后，其他牌的位置不变
+func (d *Deck) DeadWall() []card.ID {
+	return plainTiles(d.dead)
+}
+
+// Replacements 从王牌补牌的张数
+func (d *Deck) Replacements() int {
+	return d.rinshan
+}
+
+// wallStart 第 side 面牌墙的第一墩（右端）是整个牌墙的第几墩
+// 墩数不能被四整除时，多出来的墩放在后面几面牌墙
+func wallStart(side, stacks int) int {
+	return side * stacks / wallSides
+}
+
+// rollDice 掷一颗骰子
+func (d *Deck) rollDice() int {
+	return d.rng.Intn(diceFaces) + 1
+}
+
+func fillDeck(deck *Deck, tiles []card.ID) {
+	deck.tiles = make([]card.ID, len(tiles))
+	copy(deck.tiles, tiles)
+	markRed(deck.tiles, deck.reds)
+	shuffleCards(deck.rng, deck.tiles)
+}
+
+// markRed 给 reds 中每种牌在 tiles 里的第一张加上赤牌标记
+func markRed(tiles, reds []card.ID) {
+	for _, red := range reds {
+		for i, t := range tiles {
+			if t == red {
+				tiles[i] |= redTile
+				break
+			}
+		}
 	}
 }
 
-func shuffleCards(tiles []int) {
-	rand.Shuffle(len(tiles), func(i, j int) { tiles[i], tiles[j] = tiles[j], tiles[i] })
+func shuffleCards(rng *rand.Rand, tiles []card.ID) {
+	rng.Shuffle(len(tiles), func(i, j int) { tiles[i], tiles[j] = tiles[j], tiles[i] })
 }
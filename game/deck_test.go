@@ -0,0 +1,178 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+)
+
+// orderedDeck 没有洗牌、按 0 到 n-1 排列的牌墙，方便检查开牌的位置
+func orderedDeck(n int) *Deck {
+	d := &Deck{}
+	for i := 0; i < n; i++ {
+		d.tiles = append(d.tiles, card.ID(i))
+	}
+	return d
+}
+
+func TestBreak(t *testing.T) {
+	cases := []struct {
+		dice1, dice2 int
+		side         int
+		first        card.ID
+	}{
+		{3, 4, 2, 82},  // 对家的牌墙，34 墩往左数 7 墩
+		{6, 6, 3, 126}, // 上家的牌墙，51 墩往左数 12 墩
+		{1, 4, 0, 10},  // 庄家自己的牌墙
+	}
+	for _, c := range cases {
+		d := orderedDeck(136)
+		bp := d.Break(c.dice1, c.dice2)
+		if bp.Side != c.side || bp.Stack != c.dice1+c.dice2 {
+			t.Errorf("骰子 %d %d 开牌位置错误: %+v", c.dice1, c.dice2, bp)
+		}
+		if d.Len() != 136 || d.tiles[0] != c.first || d.tiles[135] != c.first-1 {
+			t.Errorf("骰子 %d %d 牌头应该是 %d, 实际 %d, 牌尾 %d", c.dice1, c.dice2, c.first, d.tiles[0], d.tiles[135])
+		}
+		if d1, d2 := d.Dice(); d1 != c.dice1 || d2 != c.dice2 {
+			t.Errorf("骰子记录错误: %d %d", d1, d2)
+		}
+	}
+}
+
+func TestDeadWall(t *testing.T) {
+	d := orderedDeck(136)
+	d.SetDeadWall(14)
+	if d.Len() != 122 || d.Stacks() != 61 {
+		t.Fatalf("留出王牌之后牌墙应该剩 122 张, 实际 %d 张", d.Len())
+	}
+	dead := d.DeadWall()
+	if len(dead) != 14 || dead[0] != 135 || dead[13] != 122 {
+		t.Fatalf("王牌错误: %v", dead)
+	}
+	if tile := d.BottomDrawOne(); tile != 135 {
+		t.Errorf("杠后应该从王牌补牌, 实际摸到 %d", tile)
+	}
+	dead = d.DeadWall()
+	if d.Len() != 121 || d.Replacements() != 1 || len(dead)-d.Replacements() != 14 || dead[14] != 121 || dead[4] != 131 {
+		t.Errorf("补牌之后王牌的张数和位置应该不变: 牌墙 %d 张, 王牌 %v", d.Len(), dead)
+	}
+	if tile := d.DrawOne(); tile != 0 {
+		t.Errorf("应该从牌头摸牌, 实际摸到 %d", tile)
+	}
+}
+
+func TestDealStartingTiles(t *testing.T) {
+	g := New(newTestPlayers(4))
+	g.DealStartingTiles()
+	if d1, d2 := g.Deck().Dice(); d1 < 1 || d1 > 6 || d2 < 1 || d2 > 6 {
+		t.Errorf("骰子点数错误: %d %d", d1, d2)
+	}
+	g.Players().ForEach(func(p *PlayerController) {
+		if len(p.Hand()) != 13 {
+			t.Errorf("%s 起手应该是 13 张, 实际 %d 张", p.Name(), len(p.Hand()))
+		}
+	})
+	if g.Deck().Len() != 136-52 {
+		t.Errorf("发牌之后牌墙应该剩 %d 张, 实际 %d 张", 136-52, g.Deck().Len())
+	}
+}
+
+func TestDeckSeed(t *testing.T) {
+	a, b := NewDeck(DeckSeed(42)), NewDeck(DeckSeed(42))
+	if a.Seed() != 42 || !reflect.DeepEqual(a.tiles, b.tiles) {
+		t.Errorf("种子一样时洗出来的牌应该一样")
+	}
+	if c := NewDeck(DeckSeed(43)); reflect.DeepEqual(a.tiles, c.tiles) {
+		t.Errorf("种子不一样时洗出来的牌应该不一样")
+	}
+	if d := NewDeck(DeckSeed(a.Seed())); !reflect.DeepEqual(a.tiles, d.tiles) {
+		t.Errorf("用记录下来的种子应该能重现牌墙")
+	}
+
+	deal := func() map[int][]card.ID {
+		g := New(newTestPlayers(4), WithDeck(DeckSeed(7)))
+		g.DealStartingTiles()
+		hands := make(map[int][]card.ID)
+		g.Players().ForEach(func(p *PlayerController) {
+			hands[p.ID()] = p.Hand()
+		})
+		return hands
+	}
+	if first, second := deal(), deal(); !reflect.DeepEqual(first, second) {
+		t.Errorf("种子一样时起手牌应该一样: %v %v", first, second)
+	}
+}
+
+func TestDeckWall(t *testing.T) {
+	wall := append([]card.ID{}, card.MahjongCards136...)
+	g := New(newTestPlayers(4), WithDeck(DeckWall(wall)))
+	g.DealStartingTiles()
+	dealer := g.Players().Peek()
+	if hand := sortedTiles(dealer.Hand()); !reflect.DeepEqual(hand[:4], wall[:4]) {
+		t.Errorf("庄家应该先抓牌墙最前面的四张: %v", hand)
+	}
+	if g.Deck().Len() != len(wall)-52 || g.Deck().DrawOne() != wall[52] {
+		t.Errorf("指定的牌墙应该按顺序摸牌")
+	}
+}
+
+func TestRedTiles(t *testing.T) {
+	d := NewDeckWithTiles(card.MahjongCards136, DeckRedTiles(card.MAHJONG_CRAK5, card.MAHJONG_DOT5))
+	reds := 0
+	for _, tile := range d.tiles {
+		if tile&redTile != 0 {
+			reds++
+			if plainTile(tile) != card.MAHJONG_CRAK5 && plainTile(tile) != card.MAHJONG_DOT5 {
+				t.Errorf("%d 不应该是赤牌", plainTile(tile))
+			}
+		}
+	}
+	if reds != 2 {
+		t.Errorf("牌墙里应该有 2 张赤牌, 实际 %d 张", reds)
+	}
+	for _, tile := range d.Wall() {
+		if tile&redTile != 0 {
+			t.Fatalf("对外的牌不应该带赤牌标记: %d", tile)
+		}
+	}
+
+	h := NewHand()
+	h.AddTiles([]card.ID{card.MAHJONG_CRAK5 | redTile, card.MAHJONG_CRAK5, card.MAHJONG_CRAK6})
+	h.RemoveTile(card.MAHJONG_CRAK5)
+	if !reflect.DeepEqual(h.RedTiles(), []card.ID{card.MAHJONG_CRAK5}) {
+		t.Errorf("有普通的牌时应该先去掉普通的牌: %v", h.RedTiles())
+	}
+	if !reflect.DeepEqual(h.Tiles(), []card.ID{card.MAHJONG_CRAK5, card.MAHJONG_CRAK6}) {
+		t.Errorf("手牌不应该带赤牌标记: %v", h.Tiles())
+	}
+	if tile := h.remove(card.MAHJONG_CRAK5); tile != card.MAHJONG_CRAK5|redTile || len(h.RedTiles()) != 0 {
+		t.Errorf("只剩赤牌时应该去掉赤牌: %d", tile)
+	}
+
+	// 摸到赤 5 摸切时打出的是摸到的赤 5，不是手里的 5
+	h.AddTiles([]card.ID{card.MAHJONG_CRAK5, card.MAHJONG_CRAK5 | redTile})
+	if tile := h.removeDrawn(card.MAHJONG_CRAK5); tile != card.MAHJONG_CRAK5|redTile || len(h.RedTiles()) != 0 {
+		t.Errorf("摸切应该打出摸到的赤牌: %d", tile)
+	}
+
+	// 玩家可以选择打出赤牌
+	player := NewPlayerController(&redPlayer{testPlayer: &testPlayer{id: 0}})
+	player.AddTiles([]card.ID{card.MAHJONG_CRAK5 | redTile, card.MAHJONG_CRAK5, card.MAHJONG_CRAK6})
+	if tile, err := player.play(State{}); err != nil || tile != card.MAHJONG_CRAK5|redTile || len(player.RedTiles()) != 0 {
+		t.Errorf("应该打出赤牌: %d %v", tile, err)
+	}
+	if _, err := player.play(State{}); err != ErrTileNotInHand {
+		t.Errorf("没有赤牌时不能打赤牌: %v", err)
+	}
+}
+
+// redPlayer 总是打出赤 5 万
+type redPlayer struct {
+	*testPlayer
+}
+
+func (p *redPlayer) Play(tiles []card.ID, gameState State) (card.ID, error) {
+	return RedTile(card.MAHJONG_CRAK5), nil
+}
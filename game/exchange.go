@@ -0,0 +1,100 @@
+package game
+
+import (
+	"errors"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/tile"
+)
+
+// ErrInvalidExchange 玩家换出的牌不是手里的三张同花色的牌
+var ErrInvalidExchange = errors.New("game: invalid exchange")
+
+// 换三张的方向，四人时值是接收的玩家相对换出的玩家在出牌顺序上的位置
+const (
+	ExchangeNext   = 1 // 换给下家
+	ExchangeAcross = 2 // 换给对家
+	ExchangePrev   = 3 // 换给上家
+)
+
+// exchangeSize 换牌的张数
+const exchangeSize = 3
+
+// ExchangeThree 换三张，在发完起手牌之后调用
+// 先收集所有玩家换出的牌，全部合法之后再掷骰子决定方向并交换，
+// 所以玩家在选牌时既不知道方向，也不知道会收到什么牌
+// 返回换牌的方向 ExchangeNext、ExchangeAcross 或 ExchangePrev
+func (g *Game) ExchangeThree() (int, error) {
+	selected := make(map[int][]card.ID, g.players.Len())
+	var err error
+	g.players.ForEach(func(player *PlayerController) {
+		if err != nil {
+			return
+		}
+		var tiles []card.ID
+		tiles, err = player.Exchange(g.ExtractState(player))
+		selected[player.ID()] = tiles
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	n := g.players.Len()
+	direction := exchangeDirection(g.deck.rollDice(), g.deck.rollDice(), n)
+	for id, tiles := range selected {
+		selected[id] = g.players.GetPlayerController(id).hand.removeTiles(tiles)
+	}
+	for id, tiles := range selected {
+		g.players.After(id, exchangeOffset(direction, n)).AddTiles(tiles)
+	}
+	return direction, nil
+}
+
+// exchangeDirection 两颗骰子的点数之和决定换牌的方向
+// 四人时除以 3，余 1 换给下家，余 2 换给对家，整除换给上家；
+// 三人时没有对家，单数换给下家，双数换给上家；两人时只能换给对家
+func exchangeDirection(dice1, dice2, players int) int {
+	sum := dice1 + dice2
+	switch {
+	case players == 2:
+		return ExchangeAcross
+	case players == 3 && sum%2 == 1:
+		return ExchangeNext
+	case players == 3:
+		return ExchangePrev
+	}
+	switch sum % 3 {
+	case 1:
+		return ExchangeNext
+	case 2:
+		return ExchangeAcross
+	}
+	return ExchangePrev
+}
+
+// exchangeOffset 接收的玩家相对换出的玩家在出牌顺序上的位置
+func exchangeOffset(direction, players int) int {
+	switch direction {
+	case ExchangeNext:
+		return 1
+	case ExchangeAcross:
+		return players / 2
+	}
+	return players - 1
+}
+
+// isValidExchange 换出的牌必须是手里的三张同花色的牌
+func isValidExchange(hand, tiles []card.ID) bool {
+	if len(tiles) != exchangeSize {
+		return false
+	}
+	handCopy := make([]card.ID, len(hand))
+	copy(handCopy, hand)
+	for _, t := range tiles {
+		if !t.IsSuit() || tile.Tile(t).Type() != tile.Tile(tiles[0]).Type() || !card.IDInSlice(t, handCopy) {
+			return false
+		}
+		handCopy = sliceDel(handCopy, t)
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package game
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+)
+
+// suitOnlyRules 只有万、条、饼的规则
+type suitOnlyRules struct {
+	BaseRules
+}
+
+func (r suitOnlyRules) Tiles() []card.ID {
+	return card.MahjongCards108
+}
+
+// badExchangePlayer 只换出两张牌
+type badExchangePlayer struct {
+	testPlayer
+}
+
+func (p *badExchangePlayer) Exchange(tiles []card.ID, gameState State) ([]card.ID, error) {
+	return tiles[:2], nil
+}
+
+func sortedTiles(tiles []card.ID) []card.ID {
+	sorted := make([]card.ID, len(tiles))
+	copy(sorted, tiles)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+func TestExchangeThree(t *testing.T) {
+	for i := 0; i < 30; i++ {
+		players := newTestPlayers(4 - i%3)
+		g := New(players, WithRuleSet(suitOnlyRules{}))
+		g.DealStartingTiles()
+		before := make(map[int][]card.ID)
+		g.Players().ForEach(func(p *PlayerController) {
+			before[p.ID()] = append([]card.ID{}, p.Hand()...)
+		})
+
+		direction, err := g.ExchangeThree()
+		if err != nil {
+			t.Fatalf("ExchangeThree error: %v", err)
+		}
+		if direction != ExchangeNext && direction != ExchangeAcross && direction != ExchangePrev {
+			t.Fatalf("换牌方向错误: %d", direction)
+		}
+		g.Players().ForEach(func(p *PlayerController) {
+			n := g.Players().Len()
+			giver := g.Players().After(p.ID(), n-exchangeOffset(direction, n))
+			expected := sliceDel(append([]card.ID{}, before[p.ID()]...), p.Player().(*testPlayer).exchanged...)
+			expected = append(expected, giver.Player().(*testPlayer).exchanged...)
+			if got, want := sortedTiles(p.Hand()), sortedTiles(expected); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s 换牌后手牌错误: 期望 %v, 实际 %v", p.Name(), want, got)
+			}
+		})
+	}
+}
+
+func TestExchangeThreeInvalid(t *testing.T) {
+	players := newTestPlayers(4)
+	players[2] = &badExchangePlayer{testPlayer{id: 2}}
+	g := New(players, WithRuleSet(suitOnlyRules{}))
+	g.DealStartingTiles()
+	if _, err := g.ExchangeThree(); err != ErrInvalidExchange {
+		t.Errorf("换出两张牌应该返回 ErrInvalidExchange, 实际 %v", err)
+	}
+	g.Players().ForEach(func(p *PlayerController) {
+		if len(p.Hand()) != 13 {
+			t.Errorf("换牌失败时不应该改变手牌: %s %v", p.Name(), p.Hand())
+		}
+	})
+}
+
+func TestIsValidExchange(t *testing.T) {
+	hand := []card.ID{1, 1, 2, 5, 11, 12, 13, 21, 31, 31, 31, 41, 42}
+	cases := []struct {
+		tiles []card.ID
+		valid bool
+	}{
+		{[]card.ID{1, 1, 2}, true},
+		{[]card.ID{11, 12, 13}, true},
+		{[]card.ID{1, 2, 11}, false},   // 不是同一花色
+		{[]card.ID{1, 1, 1}, false},    // 手里只有两张一万
+		{[]card.ID{31, 31, 31}, false}, // 字牌不能换
+		{[]card.ID{1, 2}, false},       // 张数不对
+		{[]card.ID{1, 2, 5, 1}, false}, // 张数不对
+		{[]card.ID{21, 22, 23}, false}, // 不在手牌中
+	}
+	for _, c := range cases {
+		if valid := isValidExchange(hand, c.tiles); valid != c.valid {
+			t.Errorf("isValidExchange(%v) 期望 %v, 实际 %v", c.tiles, c.valid, valid)
+		}
+	}
+}
+
+func TestExchangeDirection(t *testing.T) {
+	cases := map[[3]int]int{
+		{1, 2, 4}: ExchangePrev,
+		{1, 3, 4}: ExchangeNext,
+		{2, 3, 4}: ExchangeAcross,
+		{6, 6, 4}: ExchangePrev,
+		{5, 6, 4}: ExchangeAcross,
+		{2, 3, 3}: ExchangeNext,
+		{6, 6, 3}: ExchangePrev,
+		{1, 2, 2}: ExchangeAcross,
+	}
+	for dice, expected := range cases {
+		if d := exchangeDirection(dice[0], dice[1], dice[2]); d != expected {
+			t.Errorf("exchangeDirection(%d, %d) %d 人期望 %d, 实际 %d", dice[0], dice[1], dice[2], expected, d)
+		}
+	}
+}
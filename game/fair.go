@@ -0,0 +1,156 @@
+package game
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	mrand "math/rand"
+
+	"github.com/mikodream/mahjong/card"
+)
+
+var (
+	// ErrShuffleSealed 已经用种子洗过牌了，不能再加入玩家的随机数
+	ErrShuffleSealed = errors.New("game: shuffle is sealed")
+	// ErrCommitmentMismatch 公开的服务器种子和开局前的承诺对不上
+	ErrCommitmentMismatch = errors.New("game: seed does not match commitment")
+	// ErrWallMismatch 用公开的种子重新洗出来的牌墙和记录的不一样
+	ErrWallMismatch = errors.New("game: wall does not match seeds")
+)
+
+const serverSeedSize = 32
+
+// FairShuffle 可验证的公平洗牌（承诺-公开）
+// 发牌之前服务器公布种子的哈希作为承诺，玩家可以再各自提供一段随机数，
+// 洗牌和掷骰子的种子由服务器种子和玩家的随机数一起决定，所以服务器和任何一个玩家都不能单独控制牌墙；
+// 一局结束后公开服务器种子，任何人都可以用 VerifyShuffle 重新洗牌核对
+type FairShuffle struct {
+	serverSeed []byte
+	entropy    [][]byte
+	sealed     bool
+}
+
+// Reveal 一局结束后公开的种子
+type Reveal struct {
+	ServerSeed []byte
+	Entropy    [][]byte // 玩家提供的随机数，按加入的顺序
+}
+
+// NewFairShuffle 用 crypto/rand 生成服务器种子
+func NewFairShuffle() (*FairShuffle, error) {
+	seed := make([]byte, serverSeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return NewFairShuffleWithSeed(seed), nil
+}
+
+// NewFairShuffleWithSeed 用指定的服务器种子，主要用于测试
+func NewFairShuffleWithSeed(serverSeed []byte) *FairShuffle {
+	seed := make([]byte, len(serverSeed))
+	copy(seed, serverSeed)
+	return &FairShuffle{serverSeed: seed}
+}
+
+// Commitment 发牌之前公布的承诺：服务器种子的 SHA-256，十六进制
+func (f *FairShuffle) Commitment() string {
+	return commitment(f.serverSeed)
+}
+
+// AddEntropy 加入一个玩家提供的随机数，要在看到承诺之后、洗牌之前加入
+func (f *FairShuffle) AddEntropy(entropy []byte) error {
+	if f.sealed {
+		return ErrShuffleSealed
+	}
+	e := make([]byte, len(entropy))
+	copy(e, entropy)
+	f.entropy = append(f.entropy, e)
+	return nil
+}
+
+// DeckOption 用服务器种子和玩家的随机数洗牌，之后不能再加入随机数
+func (f *FairShuffle) DeckOption() DeckOption {
+	f.sealed = true
+	return DeckRand(fairRand(fairSeed(f.serverSeed, f.entropy)))
+}
+
+// Reveal 一局结束后公开种子
+func (f *FairShuffle) Reveal() Reveal {
+	r := Reveal{ServerSeed: make([]byte, len(f.serverSeed))}
+	copy(r.ServerSeed, f.serverSeed)
+	for _, e := range f.entropy {
+		r.Entropy = append(r.Entropy, append([]byte{}, e...))
+	}
+	return r
+}
+
+// VerifyShuffle 核对一局牌的洗牌：公开的服务器种子要和承诺对得上，
+// 用种子重新洗 tiles、掷骰子开牌，得到的牌墙要和记录的开牌时的牌墙 wall（见 Deck.Wall）一样
+func VerifyShuffle(commit string, reveal Reveal, tiles, wall []card.ID) error {
+	if commitment(reveal.ServerSeed) != commit {
+		return ErrCommitmentMismatch
+	}
+	d := NewDeckWithTiles(tiles, DeckRand(fairRand(fairSeed(reveal.ServerSeed, reveal.Entropy))))
+	d.Break(d.rollDice(), d.rollDice())
+	if len(d.wall) != len(wall) {
+		return ErrWallMismatch
+	}
+	for i := range wall {
+		if d.wall[i] != wall[i] {
+			return ErrWallMismatch
+		}
+	}
+	return nil
+}
+
+func commitment(serverSeed []byte) string {
+	sum := sha256.Sum256(serverSeed)
+	return hex.EncodeToString(sum[:])
+}
+
+// fairSeed 以服务器种子为密钥，对每段玩家随机数（带长度前缀）做 HMAC-SHA256
+func fairSeed(serverSeed []byte, entropy [][]byte) [sha256.Size]byte {
+	mac := hmac.New(sha256.New, serverSeed)
+	var size [8]byte
+	for _, e := range entropy {
+		binary.BigEndian.PutUint64(size[:], uint64(len(e)))
+		mac.Write(size[:])
+		mac.Write(e)
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], mac.Sum(nil))
+	return sum
+}
+
+// fairRand 用 fairSeed 的全部 32 个字节作为 AES-256 的密钥，CTR 模式的密钥流作为洗牌、掷骰子的随机数
+// 不能截成 int64 交给 math/rand 的 NewSource，那样只有大约 2^31 种牌墙，玩家可以从自己的手牌反推出整个牌墙
+func fairRand(key [sha256.Size]byte) *mrand.Rand {
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		panic(err) // 32 字节的密钥不会出错
+	}
+	return mrand.New(&streamSource{stream: cipher.NewCTR(block, make([]byte, aes.BlockSize))})
+}
+
+// streamSource 把密钥流包装成 math/rand 的 Source64
+type streamSource struct {
+	stream cipher.Stream
+}
+
+func (s *streamSource) Uint64() uint64 {
+	var b [8]byte
+	s.stream.XORKeyStream(b[:], b[:])
+	return binary.BigEndian.Uint64(b[:])
+}
+
+func (s *streamSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// Seed 密钥流不能重新设置种子，调用时不起作用
+func (s *streamSource) Seed(int64) {}
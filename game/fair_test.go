@@ -0,0 +1,73 @@
+package game
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+)
+
+func TestFairShuffle(t *testing.T) {
+	fair, err := NewFairShuffle()
+	if err != nil {
+		t.Fatalf("NewFairShuffle error: %v", err)
+	}
+	commit := fair.Commitment()
+	for _, e := range []string{"alice", "bob"} {
+		if err := fair.AddEntropy([]byte(e)); err != nil {
+			t.Fatalf("AddEntropy error: %v", err)
+		}
+	}
+	g := New(newTestPlayers(4), WithDeck(fair.DeckOption()))
+	if err := fair.AddEntropy([]byte("late")); err != ErrShuffleSealed {
+		t.Errorf("洗牌之后加入随机数应该返回 ErrShuffleSealed, 实际 %v", err)
+	}
+	if _, err := g.Run(context.Background()); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	wall := g.Deck().Wall()
+	reveal := fair.Reveal()
+	if err := VerifyShuffle(commit, reveal, card.MahjongCards136, wall); err != nil {
+		t.Errorf("公开种子之后应该能核对洗牌: %v", err)
+	}
+
+	forged := reveal
+	forged.ServerSeed = append([]byte{}, reveal.ServerSeed...)
+	forged.ServerSeed[0] ^= 1
+	if err := VerifyShuffle(commit, forged, card.MahjongCards136, wall); err != ErrCommitmentMismatch {
+		t.Errorf("换了服务器种子应该返回 ErrCommitmentMismatch, 实际 %v", err)
+	}
+	forged = Reveal{ServerSeed: reveal.ServerSeed, Entropy: reveal.Entropy[:1]}
+	if err := VerifyShuffle(commit, forged, card.MahjongCards136, wall); err != ErrWallMismatch {
+		t.Errorf("少了玩家的随机数应该返回 ErrWallMismatch, 实际 %v", err)
+	}
+	wall[0], wall[len(wall)-1] = wall[len(wall)-1], wall[0]
+	if wall[0] != wall[len(wall)-1] {
+		if err := VerifyShuffle(commit, reveal, card.MahjongCards136, wall); err != ErrWallMismatch {
+			t.Errorf("牌墙被换过应该返回 ErrWallMismatch, 实际 %v", err)
+		}
+	}
+}
+
+func TestFairShuffleTruncatedSeed(t *testing.T) {
+	fair := NewFairShuffleWithSeed([]byte("server seed"))
+	if err := fair.AddEntropy([]byte("alice")); err != nil {
+		t.Fatalf("AddEntropy error: %v", err)
+	}
+	commit := fair.Commitment()
+	d := NewDeck(fair.DeckOption())
+	d.Break(d.rollDice(), d.rollDice())
+	reveal := fair.Reveal()
+	if err := VerifyShuffle(commit, reveal, card.MahjongCards136, d.Wall()); err != nil {
+		t.Fatalf("公开种子之后应该能核对洗牌: %v", err)
+	}
+
+	// 只用前 8 个字节作为 math/rand 的种子洗出来的牌墙不能通过核对
+	sum := fairSeed(reveal.ServerSeed, reveal.Entropy)
+	truncated := NewDeck(DeckSeed(int64(binary.BigEndian.Uint64(sum[:8]))))
+	truncated.Break(truncated.rollDice(), truncated.rollDice())
+	if err := VerifyShuffle(commit, reveal, card.MahjongCards136, truncated.Wall()); err != ErrWallMismatch {
+		t.Errorf("截断种子洗出来的牌墙应该返回 ErrWallMismatch, 实际 %v", err)
+	}
+}
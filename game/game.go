@@ -11,6 +11,13 @@ type Game struct {
 	players *PlayerIterator
 	deck    *Deck
 	pile    *Pile
+	rules   RuleSet
+	arbiter Arbiter
+	flowers bool
+	won     map[int]bool // 已经胡牌的玩家，血战到底时不再参与
+
+	wildcards card.Wildcards // 开局翻牌决定的赖子
+	deckOpts  []DeckOption
 }
 
 func (g *Game) Players() *PlayerIterator {
@@ -25,18 +32,108 @@ func (g *Game) Pile() *Pile {
 	return g.pile
 }
 
+// Next 轮到下一个还没有胡牌的玩家
 func (g *Game) Next() *PlayerController {
 	player := g.Players().Next()
+	for i := 0; i < g.players.Len() && g.won[player.ID()]; i++ {
+		player = g.Players().Next()
+	}
 	g.pile.SetCurrentPlayer(player)
 	return player
 }
 
-func New(players []Player) *Game {
-	return &Game{
+// peek 下一个还没有胡牌的玩家，不轮转
+func (g *Game) peek() *PlayerController {
+	current := g.players.Current()
+	player := g.players.Next()
+	for i := 0; i < g.players.Len() && g.won[player.ID()]; i++ {
+		player = g.players.Next()
+	}
+	g.players.SetCurrent(current.ID())
+	return player
+}
+
+// HasWon 玩家这局是否已经胡牌
+func (g *Game) HasWon(player *PlayerController) bool {
+	return g.won[player.ID()]
+}
+
+// Option 创建游戏时的可选配置
+type Option func(g *Game)
+
+// WithRuleSet 使用指定的玩法规则，默认是 BaseRules
+func WithRuleSet(rules RuleSet) Option {
+	return func(g *Game) {
+		g.rules = rules
+	}
+}
+
+// WithDeck 创建牌墙时的配置，比如用 DeckSeed 固定种子、用 DeckWall 指定牌墙，用于测试和回放
+func WithDeck(opts ...DeckOption) Option {
+	return func(g *Game) {
+		g.deckOpts = append(g.deckOpts, opts...)
+	}
+}
+
+func New(players []Player, opts ...Option) *Game {
+	g := &Game{
 		players: newPlayerIterator(players),
-		deck:    NewDeck(),
 		pile:    NewPile(),
+		rules:   BaseRules{},
+		won:     make(map[int]bool),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	tiles := g.rules.Tiles()
+	g.deck = NewDeckWithTiles(tiles, append([]DeckOption{DeckRedTiles(g.rules.RedTiles()...)}, g.deckOpts...)...)
+	g.flowers = hasBonusTiles(tiles)
+	g.arbiter.MultiRon = g.rules.MultiRon()
+	return g
+}
+
+func (g *Game) Rules() RuleSet {
+	return g.rules
+}
+
+// CanWin 按玩法规则判断玩家的手牌是否已经胡牌
+func (g *Game) CanWin(player *PlayerController) bool {
+	return g.canWinWith(player, player.Hand())
+}
+
+// canWinWith 按玩法规则判断玩家的手牌换成 hand 之后能不能胡
+func (g *Game) canWinWith(player *PlayerController, hand []card.ID) bool {
+	return win.CanWinWith(hand, player.GetShowCardTiles(), g.winOptions()) &&
+		g.rules.AllowWin(g, player, hand)
+}
+
+// winOptions 玩法规则的胡牌牌型，加上开局翻牌决定的赖子
+func (g *Game) winOptions() win.Options {
+	opts := g.rules.WinOptions()
+	opts.Wildcards = g.Wildcards()
+	return opts
+}
+
+// Wildcards 这局牌的赖子：玩法规则固定的赖子和开局翻牌决定的赖子
+func (g *Game) Wildcards() card.Wildcards {
+	fixed := g.rules.WinOptions().Wildcards
+	wildcards := make(card.Wildcards, 0, len(fixed)+len(g.wildcards))
+	wildcards = append(wildcards, fixed...)
+	return append(wildcards, g.wildcards...)
+}
+
+// FlipWildcard 从牌墙底翻一张牌，它的下一张牌就是赖子，翻到花牌时接着翻
+// 返回翻出的牌，牌墙里没有牌时返回 0
+func (g *Game) FlipWildcard() card.ID {
+	for !g.deck.NoTiles() {
+		indicator := g.deck.BottomDrawOne()
+		if indicator.IsBonus() {
+			continue
+		}
+		g.wildcards = append(g.wildcards, card.NextTile(indicator))
+		return indicator
 	}
+	return 0
 }
 
 func (g *Game) GetPlayerTiles(id int) string {
@@ -44,20 +141,36 @@ func (g *Game) GetPlayerTiles(id int) string {
 	return tile.ToTileString(tiles)
 }
 
+// DealStartingTiles 庄家掷骰子开牌，从庄家开始轮流抓牌，每次抓四张，不够四张时每次抓一张
+// 起手 13 张时就是 4-4-4-1，庄家的第 14 张在第一次摸牌时摸
 func (g *Game) DealStartingTiles() {
-	g.players.ForEach(func(player *PlayerController) {
-		hand := g.deck.Draw(13)
-		player.AddTiles(hand)
-	})
+	g.deck.Break(g.deck.rollDice(), g.deck.rollDice())
+	dealer := g.players.Peek()
+	for dealt := 0; dealt < g.rules.HandSize(); {
+		n := dealBlock
+		if g.rules.HandSize()-dealt < dealBlock {
+			n = 1
+		}
+		for i := 0; i < g.players.Len(); i++ {
+			g.players.After(dealer.ID(), i).AddTiles(g.deck.draw(n))
+		}
+		dealt += n
+	}
+	if g.flowers {
+		g.players.ForEach(func(player *PlayerController) {
+			player.SetAsideFlowers(g.deck)
+		})
+	}
 }
 
 func (g *Game) Current() *PlayerController {
 	return g.players.Current()
 }
 
-func (g Game) ExtractState(player *PlayerController) State {
+func (g *Game) ExtractState(player *PlayerController) State {
 	playerSequence := make([]*PlayerController, 0)
 	playerShowCards := make(map[string][]*ShowCard)
+	playerFlowers := make(map[string][]card.ID)
 	specialPrivileges := make(map[int][]int)
 	canWin := make([]*PlayerController, 0)
 	originallyPlayer := g.pile.originallyPlayer
@@ -65,25 +178,24 @@ func (g Game) ExtractState(player *PlayerController) State {
 	g.players.ForEach(func(player *PlayerController) {
 		playerSequence = append(playerSequence, player)
 		playerShowCards[player.Name()] = player.GetShowCard()
-		if _, ok := g.pile.SayNoPlayer()[player.ID()]; !ok &&
+		playerFlowers[player.Name()] = player.Flowers()
+		if _, ok := g.pile.SayNoPlayer()[player.ID()]; !ok && !g.won[player.ID()] &&
 			topTile > 0 && g.pile.lastPlayer.ID() != player.ID() {
 			handWithTop := make([]card.ID, len(player.Hand()))
 			copy(handWithTop, player.Hand())
 			handWithTop = append(handWithTop, topTile)
-			if win.CanWin(handWithTop, player.GetShowCardTiles()) {
+			if g.canWinWith(player, handWithTop) {
 				canWin = append(canWin, player)
 			}
-			// Note: card package functions currently take int, will update to card.ID
-			// Casting for now or updating card package later?
-			// I will update card package to take card.ID.
-			if card.CanMingGang(player.Hand(), topTile) {
+			upstream := originallyPlayer.ID() == player.ID()
+			canGang, canPeng, canChi := g.canMeld(player.Hand(), topTile)
+			if g.canClaim(player, consts.GANG, topTile, upstream) && canGang {
 				specialPrivileges[player.ID()] = append(specialPrivileges[player.ID()], consts.GANG)
 			}
-			if card.CanPeng(player.Hand(), topTile) {
+			if g.canClaim(player, consts.PENG, topTile, upstream) && canPeng {
 				specialPrivileges[player.ID()] = append(specialPrivileges[player.ID()], consts.PENG)
 			}
-			if originallyPlayer.ID() == player.ID() &&
-				card.CanChi(player.Hand(), topTile) {
+			if g.canClaim(player, consts.CHI, topTile, upstream) && canChi {
 				specialPrivileges[player.ID()] = append(specialPrivileges[player.ID()], consts.CHI)
 			}
 		}
@@ -91,6 +203,7 @@ func (g Game) ExtractState(player *PlayerController) State {
 	return State{
 		PlayerSequence:   playerSequence,
 		PlayerShowCards:  playerShowCards,
+		PlayerFlowers:    playerFlowers,
 		CurrentPlayer:    player, // Renamed ActivePlayer -> CurrentPlayer
 		LastPlayedTile:   topTile,
 		LastPlayer:       g.pile.LastPlayer(),
@@ -101,5 +214,29 @@ func (g Game) ExtractState(player *PlayerController) State {
 		CurrentPlayerHand: player.Tiles(), // Added
 		SpecialPrivileges: specialPrivileges,
 		CanWin:            canWin,
+		Wildcards:         g.Wildcards(),
+		WinOptions:        g.winOptions(),
+	}
+}
+
+// meldWildcards 吃碰明杠时可以用来补齐的赖子，玩法规则不允许时是 nil
+func (g *Game) meldWildcards() card.Wildcards {
+	if !g.rules.WildcardMelds() {
+		return nil
 	}
+	return g.Wildcards()
+}
+
+// canMeld 手牌能不能明杠、碰、吃 tile，玩法规则允许时手里的赖子可以补齐
+func (g *Game) canMeld(hand []card.ID, tile card.ID) (gang, peng, chi bool) {
+	wildcards := g.meldWildcards()
+	if len(wildcards) == 0 {
+		return card.CanMingGang(hand, tile), card.CanPeng(hand, tile), card.CanChi(hand, tile)
+	}
+	return card.CanMingGangWith(hand, tile, wildcards), card.CanPengWith(hand, tile, wildcards), card.CanChiWith(hand, tile, wildcards)
+}
+
+// canClaim 玩法规则是否允许玩家要别人打出的牌
+func (g *Game) canClaim(player *PlayerController, op int, tile card.ID, upstream bool) bool {
+	return g.rules.AllowMeld(op) && g.rules.CanClaim(op, upstream) && g.rules.AllowPlayerMeld(g, player, op, tile)
 }
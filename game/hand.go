@@ -2,6 +2,7 @@ package game
 
 import "github.com/mikodream/mahjong/card"
 
+// Hand 手牌，包括吃碰杠的牌，里面是带赤牌标记的实体牌
 type Hand struct {
 	tiles []card.ID
 }
@@ -15,9 +16,18 @@ func (h *Hand) AddTiles(tiles []card.ID) {
 }
 
 func (h *Hand) Tiles() []card.ID {
-	tiles := make([]card.ID, len(h.tiles))
-	copy(tiles, h.tiles)
-	return tiles
+	return plainTiles(h.tiles)
+}
+
+// RedTiles 手里的赤牌
+func (h *Hand) RedTiles() []card.ID {
+	var reds []card.ID
+	for _, t := range h.tiles {
+		if t&redTile != 0 {
+			reds = append(reds, plainTile(t))
+		}
+	}
+	return reds
 }
 
 func (h *Hand) Empty() bool {
@@ -25,19 +35,54 @@ func (h *Hand) Empty() bool {
 }
 
 func (h *Hand) RemoveTile(tile card.ID) {
-	for index, tileInHand := range h.tiles {
-		if tileInHand == tile {
-			h.tiles[index] = h.tiles[len(h.tiles)-1]
-			h.tiles = h.tiles[:len(h.tiles)-1]
-			return
+	h.remove(tile)
+}
+
+func (h *Hand) RemoveTiles(tiles []card.ID) {
+	h.removeTiles(tiles)
+}
+
+// remove 去掉一张牌，有普通的牌时先去掉普通的牌，留下赤牌
+// 返回去掉的带赤牌标记的实体牌，手里没有这张牌时返回 0
+func (h *Hand) remove(tile card.ID) card.ID {
+	index := -1
+	for i, t := range h.tiles {
+		if t == tile {
+			index = i
+			break
+		}
+		if plainTile(t) == tile && index < 0 {
+			index = i
 		}
 	}
+	if index < 0 {
+		return 0
+	}
+	removed := h.tiles[index]
+	h.tiles[index] = h.tiles[len(h.tiles)-1]
+	h.tiles = h.tiles[:len(h.tiles)-1]
+	return removed
 }
 
-func (h *Hand) RemoveTiles(tiles []card.ID) {
+// removeDrawn 摸切：最后摸到的就是 tile 时去掉摸到的那张实体牌，否则和 remove 一样
+func (h *Hand) removeDrawn(tile card.ID) card.ID {
+	if n := len(h.tiles); n > 0 && plainTile(h.tiles[n-1]) == tile {
+		drawn := h.tiles[n-1]
+		h.tiles = h.tiles[:n-1]
+		return drawn
+	}
+	return h.remove(tile)
+}
+
+// removeTiles 去掉几张牌，返回去掉的实体牌
+func (h *Hand) removeTiles(tiles []card.ID) []card.ID {
+	removed := make([]card.ID, 0, len(tiles))
 	for _, t := range tiles {
-		h.RemoveTile(t)
+		if r := h.remove(t); r != 0 {
+			removed = append(removed, r)
+		}
 	}
+	return removed
 }
 
 func (h *Hand) Size() int {
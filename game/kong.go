@@ -0,0 +1,55 @@
+package game
+
+import (
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+)
+
+// addedKong 补杠（加杠）：把碰升级成杠，其他能胡这张牌的玩家可以抢杠胡
+// 被抢杠时撤销这个杠，牌交给抢杠的玩家，返回抢杠胡的操作
+func (g *Game) addedKong(player *PlayerController, tile card.ID) ([]Claim, error) {
+	showCard := player.AddGang(tile)
+	if showCard == nil {
+		return nil, ErrInvalidClaim
+	}
+
+	robbers := make([]*PlayerController, 0)
+	g.players.ForEach(func(p *PlayerController) {
+		if p.ID() == player.ID() || g.won[p.ID()] {
+			return
+		}
+		if g.canWinWith(p, append(p.Hand(), tile)) {
+			robbers = append(robbers, p)
+		}
+	})
+	if len(robbers) == 0 {
+		return nil, nil
+	}
+
+	claims := make([]Claim, 0, len(robbers))
+	for _, robber := range robbers {
+		state := g.responderState(robber, player, tile, []int{consts.WIN}, true)
+		op, tiles, err := robber.respond(state, tile)
+		if err != nil {
+			return nil, err
+		}
+		if len(tiles) == 0 {
+			continue
+		}
+		if op != consts.WIN {
+			return nil, ErrInvalidClaim
+		}
+		claims = append(claims, Claim{Player: robber, Op: op, Tiles: tiles})
+	}
+
+	winning := g.arbiter.Resolve(claims)
+	if len(winning) == 0 {
+		return nil, nil
+	}
+	showCard.ModifyQiangKong()
+	robbed := player.hand.remove(tile)
+	for _, c := range winning {
+		c.Player.AddTiles([]card.ID{robbed})
+	}
+	return winning, nil
+}
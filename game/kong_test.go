@@ -0,0 +1,54 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+)
+
+func TestAddedKong(t *testing.T) {
+	g := New(newTestPlayers(3))
+	konger := g.players.GetPlayerController(0)
+	robber := g.players.GetPlayerController(1)
+	other := g.players.GetPlayerController(2)
+
+	konger.AddTiles([]card.ID{5, 5, 5, 5, 1, 2})
+	konger.operation(consts.PENG, 1, []card.ID{5, 5, 5})
+	if tiles := konger.AddGangTiles(); len(tiles) != 1 || tiles[0] != 5 {
+		t.Fatalf("可补杠的牌错误: %v", tiles)
+	}
+	robber.AddTiles([]card.ID{3, 4, 9, 9})
+	other.AddTiles([]card.ID{1, 9, 9, 9})
+
+	robbed, err := g.addedKong(konger, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(robbed) != 1 || robbed[0].Player != robber {
+		t.Fatalf("应该被抢杠: %v", robbed)
+	}
+	if sc := konger.FindShowCard(5); !sc.IsPeng() || sc.GetTilesLen() != 3 {
+		t.Errorf("被抢的杠应该退回成碰: %v", sc)
+	}
+	if len(konger.Tiles()) != 5 || len(konger.Hand()) != 2 {
+		t.Errorf("被抢的牌应该从手里拿走: %v", konger.Tiles())
+	}
+	if !g.CanWin(robber) {
+		t.Errorf("抢杠的玩家应该胡牌: %v", robber.Hand())
+	}
+
+	robbed, err = g.addedKong(konger, 5)
+	if err != ErrInvalidClaim || robbed != nil {
+		t.Errorf("手里没有第四张不能补杠: %v %v", robbed, err)
+	}
+	konger.AddTiles([]card.ID{5})
+	robber.RemoveTile(5)
+	robber.AddTiles([]card.ID{8})
+	if robbed, err = g.addedKong(konger, 5); err != nil || len(robbed) != 0 {
+		t.Fatalf("没有人能胡时不应被抢杠: %v %v", robbed, err)
+	}
+	if sc := konger.FindShowCard(5); sc.GetOpCode() != consts.GANG || sc.GetTilesLen() != 4 {
+		t.Errorf("补杠失败: %v", sc)
+	}
+}
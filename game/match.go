@@ -0,0 +1,238 @@
+package game
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mikodream/mahjong/card"
+)
+
+// ErrMatchOver 比赛已经结束，不能再打下一局
+var ErrMatchOver = errors.New("game: match is over")
+
+const defaultRounds = 4 // 默认打四圈：东、南、西、北
+
+// roundWinds 圈风和门风的顺序
+var roundWinds = []card.ID{card.MAHJONG_EAST, card.MAHJONG_SOUTH, card.MAHJONG_WEST, card.MAHJONG_NORTH}
+
+// HandInfo 一场比赛中一局牌的局况
+type HandInfo struct {
+	Number    int             // 第几局，从 1 开始
+	Round     int             // 第几圈，从 0 开始
+	RoundWind card.ID         // 圈风（场风）
+	Dealer    int             // 庄家的玩家 ID
+	Repeat    int             // 庄家连庄的次数，换庄时清零
+	Honba     int             // 本场数：连庄或者荒庄时加一，闲家胡牌时清零
+	SeatWinds map[int]card.ID // 玩家 ID => 门风，庄家是东
+}
+
+// RulesFactory 为一场比赛的每一局创建新的玩法规则
+// hand 是这局的局况，prev 是上一局用的规则，用于延续立直棒之类的场上状态，第一局时为 nil
+type RulesFactory func(hand HandInfo, prev RuleSet) RuleSet
+
+// DealerKeeper 玩法规则自己决定庄家是否连庄，比如日本麻将流局时庄家听牌才连庄
+// 没有实现这个接口的规则在庄家胡牌或者荒庄时连庄
+type DealerKeeper interface {
+	KeepDealer(g *Game, result *HandResult) bool
+}
+
+// Match 一场比赛：按座位顺序连续打多局牌，记录圈风、庄家、本场数和累计的输赢
+// 庄家不连庄时由下家坐庄，所有玩家都坐过庄之后进入下一圈
+// 打满圈数、打满局数、有人被打飞或者有人达到目标分数时比赛结束
+type Match struct {
+	players    []Player
+	newRules   RulesFactory
+	rounds     int
+	maxHands   int
+	startScore int
+	bust       bool
+	target     int
+	deck       func(hand HandInfo) []DeckOption
+
+	hand   HandInfo
+	dealer int // 庄家在 players 中的下标
+	scores map[int]int
+	rules  RuleSet // 上一局用的规则
+	over   bool
+}
+
+// MatchOption 创建比赛时的可选配置
+type MatchOption func(m *Match)
+
+// WithRounds 打几圈，默认四圈，日本麻将的半庄是两圈
+func WithRounds(n int) MatchOption {
+	return func(m *Match) {
+		m.rounds = n
+	}
+}
+
+// WithMaxHands 最多打几局，0 表示不限
+func WithMaxHands(n int) MatchOption {
+	return func(m *Match) {
+		m.maxHands = n
+	}
+}
+
+// WithStartScore 每个玩家开局的分数，比如日本麻将的 25000 点
+func WithStartScore(score int) MatchOption {
+	return func(m *Match) {
+		m.startScore = score
+	}
+}
+
+// WithBust 有玩家的分数小于 0（被打飞）时比赛结束
+func WithBust() MatchOption {
+	return func(m *Match) {
+		m.bust = true
+	}
+}
+
+// WithTargetScore 有玩家的分数达到 score 时比赛结束
+func WithTargetScore(score int) MatchOption {
+	return func(m *Match) {
+		m.target = score
+	}
+}
+
+// WithHandDeck 每局牌墙的配置，比如按局数固定洗牌的种子，用于复现一场比赛
+func WithHandDeck(deck func(hand HandInfo) []DeckOption) MatchOption {
+	return func(m *Match) {
+		m.deck = deck
+	}
+}
+
+// NewMatch 创建比赛，players 的顺序就是座位顺序，第一个玩家是第一局的庄家
+func NewMatch(players []Player, newRules RulesFactory, opts ...MatchOption) *Match {
+	m := &Match{
+		players:  players,
+		newRules: newRules,
+		rounds:   defaultRounds,
+		scores:   make(map[int]int, len(players)),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	for _, p := range players {
+		m.scores[p.PlayerID()] = m.startScore
+	}
+	m.hand = m.handInfo(1, 0, 0, 0)
+	return m
+}
+
+// Hand 下一局（比赛结束时是最后一局）的局况
+func (m *Match) Hand() HandInfo {
+	return m.hand
+}
+
+// Scores 每个玩家累计的分数，key 是玩家 ID
+func (m *Match) Scores() map[int]int {
+	scores := make(map[int]int, len(m.scores))
+	for id, s := range m.scores {
+		scores[id] = s
+	}
+	return scores
+}
+
+// Over 比赛是否已经结束
+func (m *Match) Over() bool {
+	return m.over
+}
+
+// PlayHand 打下一局牌，累计输赢并决定下一局的庄家和圈风
+func (m *Match) PlayHand(ctx context.Context) (*Game, *HandResult, error) {
+	if m.over {
+		return nil, nil, ErrMatchOver
+	}
+	rules := m.newRules(m.hand, m.rules)
+	opts := []Option{WithRuleSet(rules)}
+	if m.deck != nil {
+		opts = append(opts, WithDeck(m.deck(m.hand)...))
+	}
+	g := New(m.seated(), opts...)
+	result, err := g.Run(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for id, s := range result.Scores {
+		m.scores[id] += s
+	}
+	m.rules = rules
+	m.advance(g, result)
+	return g, result, nil
+}
+
+// Run 一直打到比赛结束，返回每个玩家最后的分数
+func (m *Match) Run(ctx context.Context) (map[int]int, error) {
+	for !m.over {
+		if _, _, err := m.PlayHand(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return m.Scores(), nil
+}
+
+// seated 从庄家开始按座位顺序排列的玩家，Game 里第一个摸牌的就是庄家
+func (m *Match) seated() []Player {
+	seated := make([]Player, 0, len(m.players))
+	seated = append(seated, m.players[m.dealer:]...)
+	return append(seated, m.players[:m.dealer]...)
+}
+
+// advance 根据这局的结果决定下一局的局况，判断比赛是否结束
+func (m *Match) advance(g *Game, result *HandResult) {
+	dealerWon := false
+	for _, w := range result.Wins {
+		dealerWon = dealerWon || w.Winner.ID() == m.hand.Dealer
+	}
+	keep := dealerWon || result.Exhausted
+	if k, ok := g.rules.(DealerKeeper); ok {
+		keep = k.KeepDealer(g, result)
+	}
+
+	round, repeat, honba := m.hand.Round, m.hand.Repeat+1, m.hand.Honba+1
+	if !keep {
+		repeat = 0
+		if !result.Exhausted {
+			honba = 0
+		}
+		m.dealer++
+		if m.dealer == len(m.players) {
+			m.dealer = 0
+			round++
+		}
+	}
+	m.over = m.finished(round)
+	if !m.over {
+		m.hand = m.handInfo(m.hand.Number+1, round, repeat, honba)
+	}
+}
+
+// finished 打满圈数、打满局数、有人被打飞或者有人达到目标分数
+func (m *Match) finished(round int) bool {
+	if round >= m.rounds || m.maxHands > 0 && m.hand.Number >= m.maxHands {
+		return true
+	}
+	for _, s := range m.scores {
+		if m.bust && s < 0 || m.target > 0 && s >= m.target {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Match) handInfo(number, round, repeat, honba int) HandInfo {
+	seated := m.seated()
+	winds := make(map[int]card.ID, len(seated))
+	for i, p := range seated {
+		winds[p.PlayerID()] = roundWinds[i%len(roundWinds)]
+	}
+	return HandInfo{
+		Number:    number,
+		Round:     round,
+		RoundWind: roundWinds[round%len(roundWinds)],
+		Dealer:    seated[0].PlayerID(),
+		Repeat:    repeat,
+		Honba:     honba,
+		SeatWinds: winds,
+	}
+}
@@ -0,0 +1,95 @@
+package game
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+)
+
+func baseRulesFactory(hand HandInfo, prev RuleSet) RuleSet {
+	return BaseRules{}
+}
+
+// matchSeed 测试比赛洗牌的种子，每局用种子加上局数，失败时可以复现
+const matchSeed = 1
+
+func seededHands(hand HandInfo) []DeckOption {
+	return []DeckOption{DeckSeed(matchSeed + int64(hand.Number))}
+}
+
+func TestMatch(t *testing.T) {
+	m := NewMatch(newTestPlayers(4), baseRulesFactory, WithRounds(1), WithHandDeck(seededHands))
+	if h := m.Hand(); h.Number != 1 || h.Dealer != 0 || h.RoundWind != card.MAHJONG_EAST || h.SeatWinds[1] != card.MAHJONG_SOUTH {
+		t.Fatalf("种子 %d: 第一局的局况错误: %+v", matchSeed, h)
+	}
+	for hands := 0; !m.Over(); hands++ {
+		if hands > 1000 {
+			t.Fatalf("种子 %d: 比赛一直没有结束", matchSeed)
+		}
+		before := m.Hand()
+		_, result, err := m.PlayHand(context.Background())
+		if err != nil {
+			t.Fatalf("种子 %d: PlayHand error: %v", matchSeed, err)
+		}
+		if m.Over() {
+			break
+		}
+		after := m.Hand()
+		dealerWon := false
+		for _, w := range result.Wins {
+			dealerWon = dealerWon || w.Winner.ID() == before.Dealer
+		}
+		switch {
+		case dealerWon || result.Exhausted:
+			if after.Dealer != before.Dealer || after.Repeat != before.Repeat+1 || after.Honba != before.Honba+1 {
+				t.Errorf("种子 %d: 庄家胡牌或者荒庄应该连庄: %+v => %+v", matchSeed, before, after)
+			}
+		default:
+			if after.Dealer != (before.Dealer+1)%4 || after.Repeat != 0 || after.Honba != 0 {
+				t.Errorf("种子 %d: 闲家胡牌应该换庄: %+v => %+v", matchSeed, before, after)
+			}
+		}
+		if after.Number != before.Number+1 || after.SeatWinds[after.Dealer] != card.MAHJONG_EAST {
+			t.Errorf("种子 %d: 局况错误: %+v", matchSeed, after)
+		}
+	}
+	total := 0
+	for _, s := range m.Scores() {
+		total += s
+	}
+	if total != 0 {
+		t.Errorf("种子 %d: 累计输赢加起来应该为 0: %v", matchSeed, m.Scores())
+	}
+	if _, _, err := m.PlayHand(context.Background()); err != ErrMatchOver {
+		t.Errorf("种子 %d: 比赛结束后应该返回 ErrMatchOver, 实际 %v", matchSeed, err)
+	}
+}
+
+func TestMatchEnd(t *testing.T) {
+	m := NewMatch(newTestPlayers(4), baseRulesFactory, WithMaxHands(3), WithHandDeck(seededHands))
+	if _, err := m.Run(context.Background()); err != nil {
+		t.Fatalf("种子 %d: Run error: %v", matchSeed, err)
+	}
+	if m.Hand().Number != 3 {
+		t.Errorf("种子 %d: 应该打完 3 局, 实际 %d 局", matchSeed, m.Hand().Number)
+	}
+	replay := NewMatch(newTestPlayers(4), baseRulesFactory, WithMaxHands(3), WithHandDeck(seededHands))
+	if _, err := replay.Run(context.Background()); err != nil || !reflect.DeepEqual(replay.Scores(), m.Scores()) {
+		t.Errorf("种子 %d: 同样的种子应该打出同样的结果: %v %v %v", matchSeed, m.Scores(), replay.Scores(), err)
+	}
+
+	m = NewMatch(newTestPlayers(3), baseRulesFactory, WithStartScore(1), WithBust(), WithHandDeck(seededHands))
+	scores, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("种子 %d: Run error: %v", matchSeed, err)
+	}
+	bust := false
+	for _, s := range scores {
+		bust = bust || s < 0
+	}
+	if !bust && m.Hand().Round < defaultRounds-1 {
+		t.Errorf("种子 %d: 没有人被打飞也没有打满四圈就结束了: %v %+v", matchSeed, scores, m.Hand())
+	}
+}
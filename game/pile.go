@@ -3,11 +3,12 @@ package game
 import "github.com/mikodream/mahjong/card"
 
 type Pile struct {
-	tiles            []card.ID
+	tiles            []card.ID // 带赤牌标记的实体牌
 	lastPlayer       *PlayerController
 	originallyPlayer *PlayerController
 	currentPlayer    *PlayerController
 	sayNoPlayer      map[int]*PlayerController
+	discards         map[int][]card.ID // 玩家 ID => 打出过的牌，包括被吃碰杠走的
 }
 
 func (p *Pile) SetCurrentPlayer(player *PlayerController) {
@@ -54,10 +55,24 @@ func (p *Pile) Add(tile card.ID) {
 	p.tiles = append(p.tiles, tile)
 }
 
+// AddDiscard 玩家打出一张牌，同时记录是谁打的
+func (p *Pile) AddDiscard(player *PlayerController, tile card.ID) {
+	p.Add(tile)
+	if p.discards == nil {
+		p.discards = make(map[int][]card.ID)
+	}
+	p.discards[player.ID()] = append(p.discards[player.ID()], plainTile(tile))
+}
+
+// Discards 玩家打出过的所有牌，被别人吃碰杠走的也算在内，用于判断振听
+func (p *Pile) Discards(player *PlayerController) []card.ID {
+	discards := make([]card.ID, len(p.discards[player.ID()]))
+	copy(discards, p.discards[player.ID()])
+	return discards
+}
+
 func (p *Pile) Tiles() []card.ID {
-	tiles := make([]card.ID, len(p.tiles))
-	copy(tiles, p.tiles)
-	return tiles
+	return plainTiles(p.tiles)
 }
 
 func (p *Pile) ReplaceTop(tile card.ID) {
@@ -65,6 +80,11 @@ func (p *Pile) ReplaceTop(tile card.ID) {
 }
 
 func (p *Pile) Top() card.ID {
+	return plainTile(p.top())
+}
+
+// top 最后打出的实体牌，赤牌带着标记
+func (p *Pile) top() card.ID {
 	pileSize := len(p.tiles)
 	if pileSize == 0 {
 		return 0
@@ -75,5 +95,5 @@ func (p *Pile) Top() card.ID {
 func (d *Pile) BottomDrawOne() card.ID {
 	tile := d.tiles[len(d.tiles)-1]
 	d.tiles = d.tiles[0 : len(d.tiles)-1]
-	return tile
+	return plainTile(tile)
 }
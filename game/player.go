@@ -5,6 +5,13 @@ import "github.com/mikodream/mahjong/card"
 type Player interface {
 	PlayerID() int
 	NickName() string
+	// Play 出牌，返回 RedTile(tile) 表示手里有普通的 tile 时打出赤牌
 	Play(tiles []card.ID, gameState State) (card.ID, error)
 	Take(tiles []card.ID, gameState State) (int, []card.ID, error)
+	// Act 摸牌后、出牌前自己回合的操作，只在有操作可选时调用
+	// 可选的操作在 gameState.SpecialPrivileges 中: consts.AN_GANG、consts.BU_GANG、consts.WIN
+	// 返回操作和对应的牌，op 为 0 表示不操作，接着出牌
+	Act(tiles []card.ID, gameState State) (int, card.ID, error)
+	// Exchange 换三张：从手牌中选三张同花色的牌换给别人，只在玩法有换三张时调用
+	Exchange(tiles []card.ID, gameState State) ([]card.ID, error)
 }
@@ -1,15 +1,25 @@
 package game
 
 import (
+	"errors"
+
 	"github.com/mikodream/mahjong/card"
 	"github.com/mikodream/mahjong/consts"
 	"github.com/mikodream/mahjong/event"
+	"github.com/mikodream/mahjong/util"
 )
 
+// ErrTileNotInHand 玩家打出的牌不在手牌中
+var ErrTileNotInHand = errors.New("game: tile not in hand")
+
+// ErrInvalidAction 玩家在自己回合选择的操作不合法
+var ErrInvalidAction = errors.New("game: invalid action")
+
 type PlayerController struct {
 	player    Player
 	hand      *Hand
 	showCards []*ShowCard
+	flowers   []card.ID
 }
 
 func NewPlayerController(player Player) *PlayerController {
@@ -24,6 +34,38 @@ func (c *PlayerController) DarkGang(tile card.ID) {
 	c.showCards = append(c.showCards, NewShowCard(consts.GANG, 0, []card.ID{tile, tile, tile, tile}, false, false))
 }
 
+// AnGangTiles 可以暗杠的牌：手里有四张
+func (c *PlayerController) AnGangTiles() []card.ID {
+	return card.HaveGangs(c.Hand())
+}
+
+// AddGangTiles 可以补杠的牌：已经碰了，手里又有第四张
+func (c *PlayerController) AddGangTiles() []card.ID {
+	hand := c.Hand()
+	tiles := make([]card.ID, 0)
+	for _, sc := range c.showCards {
+		if sc.IsPeng() && card.IDInSlice(sc.GetTile(), hand) {
+			tiles = append(tiles, sc.GetTile())
+		}
+	}
+	return tiles
+}
+
+// AddGang 补杠，把碰过的牌升级成杠
+// 手里没有这张牌或者没有碰过时返回 nil
+func (c *PlayerController) AddGang(tile card.ID) *ShowCard {
+	if !card.IDInSlice(tile, c.Hand()) {
+		return nil
+	}
+	for _, sc := range c.showCards {
+		if sc.IsPengTile(tile) {
+			sc.ModifyPongToKong(consts.GANG, false)
+			return sc
+		}
+	}
+	return nil
+}
+
 func (c *PlayerController) operation(op, target int, tiles []card.ID) {
 	c.showCards = append(c.showCards, NewShowCard(op, target, tiles, true, false))
 }
@@ -56,23 +98,54 @@ func (c *PlayerController) AddTiles(tiles []card.ID) {
 }
 
 func (c *PlayerController) TryTopDecking(deck *Deck) {
-	extraCard := deck.DrawOne()
+	extraCard := deck.draw(1)[0]
 	c.AddTiles([]card.ID{extraCard})
 	event.PlayTile.Emit(event.PlayTilePayload{
 		PlayerName: c.player.NickName(),
-		Tile:       extraCard,
+		Tile:       plainTile(extraCard),
 	})
 }
 
 func (c *PlayerController) TryBottomDecking(deck *Deck) {
-	extraCard := deck.BottomDrawOne()
+	extraCard := deck.bottomDraw()
 	c.AddTiles([]card.ID{extraCard})
 	event.PlayTile.Emit(event.PlayTilePayload{
 		PlayerName: c.player.NickName(),
-		Tile:       extraCard,
+		Tile:       plainTile(extraCard),
 	})
 }
 
+// Flowers 花牌区的牌
+func (c *PlayerController) Flowers() []card.ID {
+	flowers := make([]card.ID, len(c.flowers))
+	copy(flowers, c.flowers)
+	return flowers
+}
+
+// SetAsideFlowers 补花：把手里的花牌放到花牌区，并从牌尾补牌，直到手里没有花牌
+// 返回这次放到花牌区的牌，牌墙摸完、补不够牌时 ok 为 false
+func (c *PlayerController) SetAsideFlowers(deck *Deck) (flowers []card.ID, ok bool) {
+	for {
+		found := false
+		for _, t := range c.Hand() {
+			if !t.IsBonus() {
+				continue
+			}
+			found = true
+			c.hand.RemoveTile(t)
+			c.flowers = append(c.flowers, t)
+			flowers = append(flowers, t)
+			if deck.NoTiles() {
+				return flowers, false
+			}
+			c.TryBottomDecking(deck)
+		}
+		if !found {
+			return flowers, true
+		}
+	}
+}
+
 func (c *PlayerController) Hand() []card.ID {
 	tiles := c.Tiles()
 	return sliceDel(tiles, c.GetShowCardTiles()...)
@@ -81,6 +154,11 @@ func (c *PlayerController) Hand() []card.ID {
 func (c *PlayerController) Tiles() []card.ID {
 	return c.hand.Tiles()
 }
+
+// RedTiles 手里的赤牌，包括吃碰杠的牌
+func (c *PlayerController) RedTiles() []card.ID {
+	return c.hand.RedTiles()
+}
 func (c *PlayerController) LastTile() card.ID {
 	return c.hand.Tiles()[len(c.hand.Tiles())-1]
 }
@@ -96,6 +174,9 @@ func (c *PlayerController) Player() Player {
 	return c.player
 }
 
+// Take 询问玩家要不要吃碰杠最后打出的牌，不经过仲裁直接执行
+//
+// Deprecated: 多个玩家抢同一张牌时不会按优先级仲裁，用 Game.Run 代替
 func (c *PlayerController) Take(gameState State, deck *Deck, pile *Pile) (int, bool, error) {
 	tiles := make([]card.ID, 0, len(c.Hand())+1)
 	tiles = append(tiles, c.Hand()...)
@@ -122,7 +203,9 @@ func (c *PlayerController) Take(gameState State, deck *Deck, pile *Pile) (int, b
 	}
 	isSelfAction := gameState.OriginallyPlayer.ID() == c.ID()
 	if !isSelfAction {
-		c.AddTiles([]card.ID{pile.BottomDrawOne()})
+		tile := pile.top()
+		pile.BottomDrawOne()
+		c.AddTiles([]card.ID{tile})
 	}
 	c.operation(op, int(pile.LastPlayer().ID()), tiles)
 	if op == consts.GANG {
@@ -131,13 +214,89 @@ func (c *PlayerController) Take(gameState State, deck *Deck, pile *Pile) (int, b
 	return op, true, nil
 }
 
+// Claim 执行仲裁后生效的吃碰杠胡，把最后打出的牌拿到手里
+// 牌池里的牌由调用方移除，这样一炮多响时每个胡牌的玩家都能拿到这张牌
+func (c *PlayerController) Claim(op int, tiles []card.ID, pile *Pile) {
+	c.AddTiles([]card.ID{pile.top()})
+	if op == consts.WIN {
+		return
+	}
+	meldTiles := make([]card.ID, len(tiles))
+	copy(meldTiles, tiles)
+	c.operation(op, pile.LastPlayer().ID(), meldTiles)
+	if op != consts.CHI {
+		// 用赖子碰杠时记下被碰杠的牌，不然 GetTile 可能是赖子
+		c.showCards[len(c.showCards)-1].tile = pile.Top()
+	}
+}
+
 func (c *PlayerController) Play(gameState State) (card.ID, error) {
+	tile, err := c.play(gameState)
+	return plainTile(tile), err
+}
+
+// play 询问玩家出牌，返回打出的实体牌
+// 有普通的牌时先打出普通的牌，玩家返回 RedTile(tile) 时打出赤牌
+func (c *PlayerController) play(gameState State) (card.ID, error) {
 	selectedTile, err := c.player.Play(c.Hand(), gameState)
 	if err != nil {
 		return 0, err
 	}
-	c.hand.RemoveTile(selectedTile)
-	return selectedTile, nil
+	tile := plainTile(selectedTile)
+	if !card.IDInSlice(tile, c.Hand()) || tile != selectedTile && !card.IDInSlice(tile, c.RedTiles()) {
+		return 0, ErrTileNotInHand
+	}
+	return c.hand.remove(selectedTile), nil
+}
+
+// Act 询问玩家自己回合的操作，只能从 ops 中选，并根据手牌校验
+// op 为 0 表示玩家不操作
+func (c *PlayerController) Act(gameState State, ops []int) (int, card.ID, error) {
+	op, tile, err := c.player.Act(c.Hand(), gameState)
+	if err != nil || op == 0 {
+		return 0, 0, err
+	}
+	if !util.IntInSlice(op, ops) {
+		return 0, 0, ErrInvalidAction
+	}
+	switch op {
+	case consts.WIN:
+		// 能不能胡已经在 ops 中判断过了
+	case consts.AN_GANG:
+		if !card.IDInSlice(tile, c.AnGangTiles()) {
+			return 0, 0, ErrInvalidAction
+		}
+	case consts.BU_GANG:
+		if !card.IDInSlice(tile, c.AddGangTiles()) {
+			return 0, 0, ErrInvalidAction
+		}
+	default:
+		return 0, 0, ErrInvalidAction
+	}
+	return op, tile, nil
+}
+
+// Exchange 询问玩家换三张要换出的牌，并根据手牌校验
+// 这里只校验，牌由调用方在所有玩家都选好之后再交换
+func (c *PlayerController) Exchange(gameState State) ([]card.ID, error) {
+	tiles, err := c.player.Exchange(c.Hand(), gameState)
+	if err != nil {
+		return nil, err
+	}
+	if !isValidExchange(c.Hand(), tiles) {
+		return nil, ErrInvalidExchange
+	}
+	selected := make([]card.ID, len(tiles))
+	copy(selected, tiles)
+	return selected, nil
+}
+
+// respond 询问玩家对别人打出的牌的操作
+func (c *PlayerController) respond(gameState State, tile card.ID) (int, []card.ID, error) {
+	tiles := make([]card.ID, 0, len(c.Hand())+1)
+	tiles = append(tiles, c.Hand()...)
+	tiles = append(tiles, tile)
+	return c.player.Take(tiles, gameState)
 }
 
 func (c *PlayerController) RemoveTile(tile card.ID) {
@@ -9,6 +9,11 @@ func (i *PlayerIterator) GetPlayerController(id int) *PlayerController {
 	return i.players[id]
 }
 
+// Len 玩家的数量
+func (i *PlayerIterator) Len() int {
+	return len(i.players)
+}
+
 func newPlayerIterator(players []Player) *PlayerIterator {
 	var playerIDs []int
 	playerMap := make(map[int]*PlayerController, len(players))
@@ -37,3 +42,24 @@ func (i *PlayerIterator) ForEach(function func(player *PlayerController)) {
 func (i *PlayerIterator) Next() *PlayerController {
 	return i.players[i.cycler.Next()]
 }
+
+// Peek 返回下一个玩家，但不轮转
+func (i *PlayerIterator) Peek() *PlayerController {
+	return i.players[i.cycler.Peek()]
+}
+
+// SetCurrent 将当前玩家切换为指定玩家，用于吃碰杠后插队出牌
+func (i *PlayerIterator) SetCurrent(id int) {
+	i.cycler.SetCurrent(id)
+}
+
+// After 按出牌顺序排在 id 之后第 n 个的玩家，n 为 1 时是下家
+func (i *PlayerIterator) After(id, n int) *PlayerController {
+	ids := i.cycler.Elements()
+	for k, e := range ids {
+		if e == id {
+			return i.players[ids[(k+n)%len(ids)]]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package game
+
+import (
+	"context"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+	"github.com/mikodream/mahjong/win"
+)
+
+// RuleSet 玩法规则，不同地区的玩法各自实现
+// 一般嵌入 BaseRules，只覆盖不一样的部分
+type RuleSet interface {
+	// Tiles 牌墙里的所有牌，洗牌由 Deck 负责
+	Tiles() []card.ID
+	// RedTiles 赤牌：牌墙里这些牌各有一张是红的，比如日本麻将的赤 5，见 PlayerController.RedTiles
+	RedTiles() []card.ID
+	// HandSize 起手的张数
+	HandSize() int
+	// AllowMeld 是否有这种明牌：consts.CHI、PENG、GANG、AN_GANG、BU_GANG
+	AllowMeld(op int) bool
+	// WinOptions 允许胡的特殊牌型
+	WinOptions() win.Options
+	// AllowWin 牌型已经胡了之后，玩法规则的额外限制，比如四川麻将没打缺不能胡
+	AllowWin(g *Game, player *PlayerController, hand []card.ID) bool
+	// CanClaim 能不能要别人打出的牌，upstream 表示出牌的是自己的上家
+	CanClaim(op int, upstream bool) bool
+	// AllowPlayerMeld 这个玩家现在能不能用 tile 吃碰杠别人打出的牌，或者在自己回合暗杠、补杠 tile，
+	// 比如日本麻将立直后不能鸣牌，只能在不改变听牌时暗杠
+	AllowPlayerMeld(g *Game, player *PlayerController, op int, tile card.ID) bool
+	// ForcedDiscard 玩家只能打出的牌，比如日本麻将立直后只能摸切，返回 false 时由玩家自己选
+	ForcedDiscard(g *Game, player *PlayerController) (card.ID, bool)
+	// MultiRon 是否允许一炮多响
+	MultiRon() bool
+	// WildcardMelds 吃、碰、明杠别人打出的牌时，手里的赖子能不能补齐缺的牌
+	WildcardMelds() bool
+	// AfterDeal 发完起手牌之后、庄家摸牌之前调用，用于定缺、换牌等
+	AfterDeal(ctx context.Context, g *Game) error
+	// AfterDiscard 玩家出牌之后、其他玩家吃碰杠胡之前调用，用于立直、振听等
+	AfterDiscard(g *Game, player *PlayerController, tile card.ID) error
+	// HandOver 有人胡牌之后这局牌是否结束，血战到底时胡牌的玩家退出，其他玩家接着打
+	HandOver(g *Game, result *HandResult) bool
+	// Score 一局结束后结算，返回每个玩家的输赢分，key 是玩家 ID，算不出胡牌的分数时返回错误
+	Score(g *Game, result *HandResult) (map[int]int, error)
+}
+
+// BaseRules 默认规则
+// 136 张牌（可选 144 张带花牌），起手 13 张，只能吃上家，可以胡七对和十三幺
+// 三人时去掉二万到八万（108 张），不能吃；两人时只用条、饼（72 张），可以吃对方
+// 结算时点炮的玩家给胡牌的玩家 1 分，自摸时其他玩家每人给 1 分
+// 赖子默认只在胡牌、听牌时百搭，吃碰杠还是要用原来的牌，设置 AllowWildcardMelds 后吃碰明杠也可以用赖子补齐
+type BaseRules struct {
+	Flowers            bool           // 使用八张花牌
+	AllowMultiRon      bool           // 允许一炮多响
+	Wildcards          card.Wildcards // 固定的赖子，比如红中
+	FlipWildcard       bool           // 发完牌后从牌墙底翻一张牌，它的下一张牌是赖子
+	AllowWildcardMelds bool           // 吃碰明杠时可以用赖子补齐
+	Players            int            // 玩家人数，0 表示四人
+	Deck               []card.ID      // 自定义牌墙，比如两人只用万子的 card.MahjongCards36，为空时按人数决定
+}
+
+func (r BaseRules) Tiles() []card.ID {
+	tiles := r.Deck
+	if tiles == nil {
+		switch r.Players {
+		case 3:
+			tiles = card.MahjongCardsSanma
+		case 2:
+			tiles = card.MahjongCards72
+		default:
+			tiles = card.MahjongCards136
+		}
+	}
+	if r.Flowers && !hasBonusTiles(tiles) {
+		return append(append([]card.ID{}, tiles...), card.BonusTiles...)
+	}
+	return tiles
+}
+
+func (r BaseRules) RedTiles() []card.ID {
+	return nil
+}
+
+func (r BaseRules) HandSize() int {
+	return 13
+}
+
+func (r BaseRules) AllowMeld(op int) bool {
+	return true
+}
+
+func (r BaseRules) WinOptions() win.Options {
+	opts := win.DefaultOptions
+	opts.Wildcards = r.Wildcards
+	return opts
+}
+
+func (r BaseRules) AllowWin(g *Game, player *PlayerController, hand []card.ID) bool {
+	return true
+}
+
+func (r BaseRules) CanClaim(op int, upstream bool) bool {
+	return op != consts.CHI || upstream && r.Players != 3
+}
+
+func (r BaseRules) AllowPlayerMeld(g *Game, player *PlayerController, op int, tile card.ID) bool {
+	return true
+}
+
+func (r BaseRules) ForcedDiscard(g *Game, player *PlayerController) (card.ID, bool) {
+	return 0, false
+}
+
+func (r BaseRules) MultiRon() bool {
+	return r.AllowMultiRon
+}
+
+func (r BaseRules) WildcardMelds() bool {
+	return r.AllowWildcardMelds
+}
+
+func (r BaseRules) AfterDeal(ctx context.Context, g *Game) error {
+	if r.FlipWildcard {
+		g.FlipWildcard()
+	}
+	return nil
+}
+
+func (r BaseRules) AfterDiscard(g *Game, player *PlayerController, tile card.ID) error {
+	return nil
+}
+
+func (r BaseRules) HandOver(g *Game, result *HandResult) bool {
+	return len(result.Wins) > 0
+}
+
+func (r BaseRules) Score(g *Game, result *HandResult) (map[int]int, error) {
+	scores := make(map[int]int)
+	for _, w := range result.Wins {
+		if w.SelfDrawn {
+			g.players.ForEach(func(p *PlayerController) {
+				if p.ID() != w.Winner.ID() {
+					scores[p.ID()]--
+					scores[w.Winner.ID()]++
+				}
+			})
+			continue
+		}
+		scores[w.Discarder.ID()]--
+		scores[w.Winner.ID()]++
+	}
+	return scores, nil
+}
+
+// hasBonusTiles 牌墙里是否有花牌
+func hasBonusTiles(tiles []card.ID) bool {
+	for _, t := range tiles {
+		if t.IsBonus() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+)
+
+// noChiRules 不能吃的规则
+type noChiRules struct {
+	BaseRules
+}
+
+func (r noChiRules) AllowMeld(op int) bool {
+	return op != consts.CHI
+}
+
+func TestRuleSet(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		g := New(newTestPlayers(4), WithRuleSet(noChiRules{}))
+		result, err := g.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+		total := 0
+		g.Players().ForEach(func(p *PlayerController) {
+			for _, sc := range p.GetShowCard() {
+				if sc.GetOpCode() == consts.CHI {
+					t.Errorf("不能吃的规则下吃了牌: %v", sc)
+				}
+			}
+			total += result.Scores[p.ID()]
+		})
+		if total != 0 {
+			t.Errorf("输赢分加起来应该为 0: %v", result.Scores)
+		}
+		if result.Exhausted && len(result.Scores) != 0 {
+			t.Errorf("荒庄不应该有输赢: %v", result.Scores)
+		}
+	}
+}
+
+func TestPlayerCount(t *testing.T) {
+	cases := []struct {
+		players int
+		tiles   int
+		chi     bool
+	}{
+		{3, 108, false},
+		{2, 72, true},
+	}
+	for _, c := range cases {
+		rules := BaseRules{Players: c.players}
+		if g := New(newTestPlayers(c.players), WithRuleSet(rules)); g.Deck().Len() != c.tiles {
+			t.Errorf("%d 人的牌墙应该有 %d 张, 实际 %d 张", c.players, c.tiles, g.Deck().Len())
+		}
+		if rules.CanClaim(consts.CHI, true) != c.chi {
+			t.Errorf("%d 人能不能吃上家: 期望 %v", c.players, c.chi)
+		}
+		for i := 0; i < 20; i++ {
+			g := New(newTestPlayers(c.players), WithRuleSet(rules))
+			result, err := g.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run error: %v", err)
+			}
+			total := 0
+			for _, s := range result.Scores {
+				total += s
+			}
+			if total != 0 {
+				t.Errorf("输赢分加起来应该为 0: %v", result.Scores)
+			}
+			g.Players().ForEach(func(p *PlayerController) {
+				for _, tile := range p.Tiles() {
+					if c.players == 3 && tile > card.MAHJONG_CRAK1 && tile < card.MAHJONG_CRAK9 {
+						t.Errorf("三人麻将不应该有二万到八万: %v", p.Tiles())
+					}
+				}
+				for _, sc := range p.GetShowCard() {
+					if sc.GetOpCode() == consts.CHI && !c.chi {
+						t.Errorf("%d 人时不能吃: %v", c.players, sc)
+					}
+				}
+			})
+		}
+	}
+}
+
+// tsumogiriRules 不能鸣牌、不能杠，摸什么打什么
+type tsumogiriRules struct {
+	BaseRules
+}
+
+func (r tsumogiriRules) AllowPlayerMeld(g *Game, player *PlayerController, op int, tile card.ID) bool {
+	return false
+}
+
+func (r tsumogiriRules) ForcedDiscard(g *Game, player *PlayerController) (card.ID, bool) {
+	return player.LastTile(), true
+}
+
+// noPlayPlayer 被询问出牌时返回错误
+type noPlayPlayer struct {
+	*testPlayer
+}
+
+func (p noPlayPlayer) Play(tiles []card.ID, gameState State) (card.ID, error) {
+	return 0, errors.New("不应该询问出牌")
+}
+
+func TestPlayerRestrictions(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		players := make([]Player, 0, 4)
+		for _, p := range newTestPlayers(4) {
+			players = append(players, noPlayPlayer{p.(*testPlayer)})
+		}
+		g := New(players, WithRuleSet(tsumogiriRules{}))
+		if _, err := g.Run(context.Background()); err != nil {
+			t.Fatalf("规则指定了要打的牌时不应该询问玩家: %v", err)
+		}
+		g.Players().ForEach(func(p *PlayerController) {
+			if len(p.GetShowCard()) > 0 {
+				t.Errorf("不能鸣牌、杠: %v", p.GetShowCard())
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package game
+
+import (
+	"context"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+	"github.com/mikodream/mahjong/event"
+	"github.com/mikodream/mahjong/util"
+)
+
+// Win 一次胡牌记录
+type Win struct {
+	Winner    *PlayerController
+	Discarder *PlayerController // 点炮的玩家，自摸时为 nil
+	Tile      card.ID           // 胡的那张牌
+	SelfDrawn bool              // 是否自摸
+	RobKong   bool              // 是否抢杠胡
+	Flower    bool              // 是否花胡
+}
+
+// HandResult 一局牌的结果
+type HandResult struct {
+	Wins      []Win       // 胡牌记录，一炮多响时有多条
+	Exhausted bool        // 牌墙是否摸完了，血战到底时摸完之前可能已经有人胡了
+	Scores    map[int]int // 结算的输赢分，key 是玩家 ID
+}
+
+// Run 从发牌开始完整地打完一局牌，并按玩法规则结算
+// 摸牌 → 出牌 → 其他玩家吃碰杠胡 → 杠后补牌，直到有人胡牌或者牌墙摸完
+func (g *Game) Run(ctx context.Context) (*HandResult, error) {
+	result := &HandResult{}
+	if err := g.play(ctx, result); err != nil {
+		return nil, err
+	}
+	scores, err := g.rules.Score(g, result)
+	if err != nil {
+		return nil, err
+	}
+	result.Scores = scores
+	return result, nil
+}
+
+// 摸牌的方式
+const (
+	noDraw     = iota // 吃、碰之后直接出牌
+	drawTop           // 从牌头摸牌
+	drawBottom        // 杠之后从牌尾补牌
+)
+
+// play 打一局牌，结果记录在 result 中
+func (g *Game) play(ctx context.Context, result *HandResult) error {
+	g.DealStartingTiles()
+	if err := g.rules.AfterDeal(ctx, g); err != nil {
+		return err
+	}
+	player := g.Next()
+	g.players.ForEach(func(p *PlayerController) {
+		if len(p.Flowers()) == len(card.BonusTiles) {
+			result.Wins = append(result.Wins, Win{Winner: p, Tile: p.Flowers()[len(card.BonusTiles)-1], SelfDrawn: true, Flower: true})
+		}
+	})
+	if len(result.Wins) > 0 {
+		next, over := g.afterWins(result, 0)
+		if over {
+			return nil
+		}
+		player = next
+	}
+
+	source := drawTop
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if source != noDraw {
+			wins := len(result.Wins)
+			done := g.draw(player, source == drawBottom, result)
+			if !done {
+				var err error
+				if done, err = g.selfTurn(player, result); err != nil {
+					return err
+				}
+			}
+			if done {
+				if result.Exhausted {
+					return nil
+				}
+				next, over := g.afterWins(result, wins)
+				if over {
+					return nil
+				}
+				player, source = next, drawTop
+				continue
+			}
+		}
+
+		tile, err := g.discard(player)
+		if err != nil {
+			return err
+		}
+		claims, err := g.claim(player, tile)
+		if err != nil {
+			return err
+		}
+		switch {
+		case len(claims) == 0:
+			player, source = g.Next(), drawTop
+		case claims[0].Op == consts.WIN:
+			wins := len(result.Wins)
+			for _, c := range claims {
+				result.Wins = append(result.Wins, Win{Winner: c.Player, Discarder: player, Tile: tile})
+			}
+			next, over := g.afterWins(result, wins)
+			if over {
+				return nil
+			}
+			player, source = next, drawTop
+		case claims[0].Op == consts.GANG:
+			player, source = claims[0].Player, drawBottom
+		default:
+			player, source = claims[0].Player, noDraw
+		}
+	}
+}
+
+// afterWins 记录 result.Wins[from:] 中胡牌的玩家，按玩法规则判断这局牌是否结束
+// 没有结束（血战到底）时，由最后一个胡牌的玩家的下家接着摸牌
+func (g *Game) afterWins(result *HandResult, from int) (*PlayerController, bool) {
+	for _, w := range result.Wins[from:] {
+		g.won[w.Winner.ID()] = true
+	}
+	if g.rules.HandOver(g, result) {
+		return nil, true
+	}
+	g.players.SetCurrent(result.Wins[len(result.Wins)-1].Winner.ID())
+	return g.Next(), false
+}
+
+// selfTurn 摸牌之后、出牌之前自己的回合：自摸、暗杠、补杠
+// 杠了之后从牌尾补牌，再继续询问，返回这局牌是否已经结束
+func (g *Game) selfTurn(player *PlayerController, result *HandResult) (bool, error) {
+	for {
+		ops := g.selfActions(player)
+		if len(ops) == 0 {
+			return false, nil
+		}
+		state := g.ExtractState(player)
+		state.OriginallyPlayer = player
+		state.LastPlayedTile = 0
+		state.CanWin = nil
+		if util.IntInSlice(consts.WIN, ops) {
+			state.CanWin = []*PlayerController{player}
+		}
+		state.SpecialPrivileges = map[int][]int{player.ID(): ops}
+
+		op, tile, err := player.Act(state, ops)
+		if err != nil {
+			return false, err
+		}
+		if (op == consts.AN_GANG || op == consts.BU_GANG) && !card.IDInSlice(tile, g.kongTiles(player, op)) {
+			return false, ErrInvalidAction
+		}
+		switch op {
+		case 0:
+			return false, nil
+		case consts.WIN:
+			result.Wins = append(result.Wins, Win{Winner: player, Tile: player.LastTile(), SelfDrawn: true})
+			return true, nil
+		case consts.AN_GANG:
+			player.DarkGang(tile)
+		case consts.BU_GANG:
+			robbed, err := g.addedKong(player, tile)
+			if err != nil {
+				return false, err
+			}
+			if len(robbed) > 0 {
+				for _, c := range robbed {
+					result.Wins = append(result.Wins, Win{Winner: c.Player, Discarder: player, Tile: tile, RobKong: true})
+				}
+				return true, nil
+			}
+		}
+		if g.draw(player, true, result) {
+			return true, nil
+		}
+	}
+}
+
+// selfActions 摸牌后自己回合可以选择的操作
+func (g *Game) selfActions(player *PlayerController) []int {
+	ops := make([]int, 0, 3)
+	if g.CanWin(player) {
+		ops = append(ops, consts.WIN)
+	}
+	if len(g.kongTiles(player, consts.AN_GANG)) > 0 {
+		ops = append(ops, consts.AN_GANG)
+	}
+	if len(g.kongTiles(player, consts.BU_GANG)) > 0 {
+		ops = append(ops, consts.BU_GANG)
+	}
+	return ops
+}
+
+// kongTiles 玩法规则允许玩家暗杠（op 为 consts.AN_GANG）或者补杠（consts.BU_GANG）的牌
+func (g *Game) kongTiles(player *PlayerController, op int) []card.ID {
+	if !g.rules.AllowMeld(op) {
+		return nil
+	}
+	candidates := player.AnGangTiles()
+	if op == consts.BU_GANG {
+		candidates = player.AddGangTiles()
+	}
+	tiles := make([]card.ID, 0, len(candidates))
+	for _, t := range candidates {
+		if g.rules.AllowPlayerMeld(g, player, op, t) {
+			tiles = append(tiles, t)
+		}
+	}
+	return tiles
+}
+
+// draw 从牌头或牌尾摸一张牌，开了花牌时自动补花
+// 返回这局牌是否已经结束（荒庄或者花胡）
+func (g *Game) draw(player *PlayerController, bottom bool, result *HandResult) bool {
+	if g.deck.NoTiles() {
+		result.Exhausted = true
+		return true
+	}
+	if bottom {
+		player.TryBottomDecking(g.deck)
+	} else {
+		player.TryTopDecking(g.deck)
+	}
+	if !g.flowers {
+		return false
+	}
+	flowers, ok := player.SetAsideFlowers(g.deck)
+	if len(flowers) > 0 {
+		if wins := g.flowerWins(player, flowers[len(flowers)-1]); len(wins) > 0 {
+			result.Wins = append(result.Wins, wins...)
+			return true
+		}
+	}
+	if !ok {
+		result.Exhausted = true
+		return true
+	}
+	return false
+}
+
+// flowerWins 花胡：集齐八张花牌直接胡（八仙过海）
+// 有人摸到第八张花牌时，手里有七张花牌的玩家抢这张花胡牌（七抢一）
+func (g *Game) flowerWins(player *PlayerController, flower card.ID) []Win {
+	total := len(card.BonusTiles)
+	if len(player.Flowers()) == total {
+		return []Win{{Winner: player, Tile: flower, SelfDrawn: true, Flower: true}}
+	}
+	if len(player.Flowers()) != 1 {
+		return nil
+	}
+	var wins []Win
+	g.players.ForEach(func(p *PlayerController) {
+		if !g.won[p.ID()] && len(p.Flowers()) == total-1 {
+			wins = append(wins, Win{Winner: p, Discarder: player, Tile: flower, Flower: true})
+		}
+	})
+	return wins
+}
+
+// discard 当前玩家出一张牌，放到牌池里，玩法规则指定了要打的牌时不询问玩家
+func (g *Game) discard(player *PlayerController) (card.ID, error) {
+	var physical card.ID
+	if tile, forced := g.rules.ForcedDiscard(g, player); forced && card.IDInSlice(tile, player.Hand()) {
+		physical = player.hand.removeDrawn(tile)
+	} else {
+		var err error
+		if physical, err = player.play(g.ExtractState(player)); err != nil {
+			return 0, err
+		}
+	}
+	tile := plainTile(physical)
+	g.pile.AddDiscard(player, physical)
+	g.pile.SetLastPlayer(player)
+	g.pile.SetOriginallyPlayer(g.peek())
+	event.TilePlayed.Emit(event.TilePlayedPayload{
+		PlayerName: player.Name(),
+		Tile:       tile,
+	})
+	if err := g.rules.AfterDiscard(g, player, tile); err != nil {
+		return 0, err
+	}
+	return tile, nil
+}
@@ -0,0 +1,269 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+	"github.com/mikodream/mahjong/tile"
+	"github.com/mikodream/mahjong/ting"
+	"github.com/mikodream/mahjong/win"
+)
+
+// testPlayer 简单的机器人：能胡就胡，能杠就杠，能碰就碰，能吃就吃，优先打出能听牌的牌，否则打出最孤立的牌
+type testPlayer struct {
+	id        int
+	exchanged []card.ID // 换三张时换出的牌
+}
+
+func (p *testPlayer) PlayerID() int {
+	return p.id
+}
+
+func (p *testPlayer) NickName() string {
+	return string(rune('A' + p.id))
+}
+
+func (p *testPlayer) Play(tiles []card.ID, gameState State) (card.ID, error) {
+	// 能听牌时打出最小的那张，map 的顺序是随机的，这样同样的种子才能打出同样的牌局
+	var tingDiscard card.ID
+	for discard := range ting.GetTingMap(tiles, nil) {
+		if tingDiscard == 0 || discard < tingDiscard {
+			tingDiscard = discard
+		}
+	}
+	if tingDiscard != 0 {
+		return tingDiscard, nil
+	}
+	best, bestScore := tiles[0], 100
+	for _, t := range tiles {
+		score := 0
+		for _, o := range tiles {
+			if d := int(o) - int(t); d >= -2 && d <= 2 {
+				score++
+			}
+		}
+		if score < bestScore {
+			best, bestScore = t, score
+		}
+	}
+	return best, nil
+}
+
+// errOthersHand 询问玩家时给的是别人的状态，会看到别人的手牌
+var errOthersHand = errors.New("game: state shows another player's hand")
+
+func (p *testPlayer) Take(tiles []card.ID, gameState State) (int, []card.ID, error) {
+	if gameState.CurrentPlayer.ID() != p.id {
+		return 0, nil, errOthersHand
+	}
+	top := gameState.LastPlayedTile
+	for _, c := range gameState.CanWin {
+		if c.ID() == p.id {
+			return consts.WIN, []card.ID{top}, nil
+		}
+	}
+	hand := tiles[:len(tiles)-1]
+	for _, op := range gameState.SpecialPrivileges[p.id] {
+		if op == consts.PENG {
+			return consts.PENG, pengTiles(hand, top, gameState.Wildcards), nil
+		}
+		if op == consts.CHI {
+			chis := card.CanChiTiles(hand, top)
+			if len(chis) == 0 {
+				chis = card.CanChiTilesWith(hand, top, gameState.Wildcards)
+			}
+			return consts.CHI, []card.ID{chis[0][0], chis[0][1], top}, nil
+		}
+	}
+	return 0, nil, nil
+}
+
+// pengTiles 碰 top 的三张牌，手里的 top 不够两张时用赖子补
+func pengTiles(hand []card.ID, top card.ID, wildcards card.Wildcards) []card.ID {
+	tiles := []card.ID{top}
+	for _, t := range hand {
+		if t == top && len(tiles) < 3 {
+			tiles = append(tiles, t)
+		}
+	}
+	for _, t := range hand {
+		if wildcards.Contains(t) && len(tiles) < 3 {
+			tiles = append(tiles, t)
+		}
+	}
+	return tiles
+}
+
+func (p *testPlayer) Act(tiles []card.ID, gameState State) (int, card.ID, error) {
+	ops := gameState.SpecialPrivileges[p.id]
+	switch {
+	case len(ops) == 0:
+		return 0, 0, nil
+	case ops[0] == consts.WIN:
+		return consts.WIN, 0, nil
+	case ops[0] == consts.AN_GANG:
+		return consts.AN_GANG, card.HaveGangs(tiles)[0], nil
+	case ops[0] == consts.BU_GANG:
+		return consts.BU_GANG, gameState.CurrentPlayer.AddGangTiles()[0], nil
+	}
+	return 0, 0, nil
+}
+
+// Exchange 换出张数最多的花色的前三张，没有一门花色够三张时返回错误
+func (p *testPlayer) Exchange(tiles []card.ID, gameState State) ([]card.ID, error) {
+	suits := make(map[int][]card.ID)
+	best := -1
+	for _, t := range tiles {
+		if !t.IsSuit() {
+			continue
+		}
+		suit := tile.Tile(t).Type()
+		suits[suit] = append(suits[suit], t)
+		if best < 0 || len(suits[suit]) > len(suits[best]) {
+			best = suit
+		}
+	}
+	if len(suits[best]) < exchangeSize {
+		return nil, ErrInvalidExchange
+	}
+	p.exchanged = suits[best][:exchangeSize]
+	return p.exchanged, nil
+}
+
+func newTestPlayers(n int) []Player {
+	players := make([]Player, 0, n)
+	for i := 0; i < n; i++ {
+		players = append(players, &testPlayer{id: i})
+	}
+	return players
+}
+
+func TestRun(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		g := New(newTestPlayers(4), WithRuleSet(BaseRules{AllowMultiRon: i%2 == 0}), WithDeck(DeckSeed(int64(i))))
+		result, err := g.Run(context.Background())
+		if err != nil {
+			t.Fatalf("种子 %d: Run error: %v", i, err)
+		}
+		if result.Exhausted {
+			if len(result.Wins) != 0 || !g.Deck().NoTiles() {
+				t.Errorf("种子 %d: 荒庄结果错误: %+v", i, result)
+			}
+			continue
+		}
+		if len(result.Wins) == 0 {
+			t.Fatalf("种子 %d: 没有人胡也没有荒庄: %+v", i, result)
+		}
+		for _, w := range result.Wins {
+			if !w.Flower && !g.CanWin(w.Winner) {
+				t.Errorf("种子 %d: %s 的牌没有胡: %v", i, w.Winner.Name(), w.Winner.Hand())
+			}
+			if w.SelfDrawn == (w.Discarder != nil) {
+				t.Errorf("种子 %d: 自摸和点炮状态不一致: %+v", i, w)
+			}
+		}
+	}
+}
+
+func TestRunWithFlowers(t *testing.T) {
+	for i := 0; i < 30; i++ {
+		g := New(newTestPlayers(4), WithRuleSet(BaseRules{Flowers: true}), WithDeck(DeckSeed(int64(i))))
+		result, err := g.Run(context.Background())
+		if err != nil {
+			t.Fatalf("种子 %d: Run error: %v", i, err)
+		}
+		total := len(g.Pile().Tiles())
+		flowers := 0
+		g.Players().ForEach(func(p *PlayerController) {
+			for _, c := range p.Hand() {
+				if c.IsBonus() {
+					t.Errorf("种子 %d: %s 手里还有花牌: %v", i, p.Name(), p.Hand())
+				}
+			}
+			for _, c := range p.Flowers() {
+				if !c.IsBonus() {
+					t.Errorf("种子 %d: %s 花牌区有普通牌: %v", i, p.Name(), p.Flowers())
+				}
+			}
+			flowers += len(p.Flowers())
+			total += len(p.Tiles()) + len(p.Flowers())
+		})
+		if result.Exhausted && (total != 144 || flowers != 8) {
+			t.Errorf("种子 %d: 荒庄时所有牌都应该摸完: %d 张, %d 张花", i, total, flowers)
+		}
+	}
+}
+
+func TestFlowerWins(t *testing.T) {
+	g := New(newTestPlayers(3), WithRuleSet(BaseRules{Flowers: true}))
+	seven := g.players.GetPlayerController(0)
+	drawer := g.players.GetPlayerController(1)
+	seven.flowers = append(seven.flowers, card.BonusTiles[:7]...)
+	drawer.flowers = append(drawer.flowers, card.BonusTiles[7])
+
+	wins := g.flowerWins(drawer, card.BonusTiles[7])
+	if len(wins) != 1 || wins[0].Winner != seven || wins[0].Discarder != drawer || !wins[0].Flower {
+		t.Errorf("七抢一验证失败: %+v", wins)
+	}
+
+	drawer.flowers = nil
+	seven.flowers = append(seven.flowers, card.BonusTiles[7])
+	wins = g.flowerWins(seven, card.BonusTiles[7])
+	if len(wins) != 1 || wins[0].Winner != seven || !wins[0].SelfDrawn {
+		t.Errorf("八仙过海验证失败: %+v", wins)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := New(newTestPlayers(4)).Run(ctx); err != context.Canceled {
+		t.Errorf("期望 context.Canceled, 实际 %v", err)
+	}
+}
+
+func TestRunWithWildcards(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		rules := BaseRules{Wildcards: card.Wildcards{card.MAHJONG_RED}}
+		if i%2 == 1 {
+			rules = BaseRules{FlipWildcard: true}
+		}
+		rules.AllowWildcardMelds = i%4 >= 2
+		g := New(newTestPlayers(4), WithRuleSet(rules), WithDeck(DeckSeed(int64(i))))
+		result, err := g.Run(context.Background())
+		if err != nil {
+			t.Fatalf("种子 %d: Run error: %v", i, err)
+		}
+		if len(g.Wildcards()) != 1 {
+			t.Fatalf("种子 %d: 赖子数量错误: %v", i, g.Wildcards())
+		}
+		opts := win.DefaultOptions
+		opts.Wildcards = g.Wildcards()
+		for _, w := range result.Wins {
+			if !win.CanWinWith(w.Winner.Hand(), w.Winner.GetShowCardTiles(), opts) {
+				t.Errorf("种子 %d: %s 的牌没有胡: %v", i, w.Winner.Name(), w.Winner.Hand())
+			}
+		}
+		g.players.ForEach(func(p *PlayerController) {
+			for _, sc := range p.GetShowCard() {
+				// 全是赖子的是把赖子当作原来的牌碰杠
+				normal, jokers := g.Wildcards().Split(sc.GetTiles())
+				if jokers == 0 || len(normal) == 0 {
+					continue
+				}
+				if sc.GetOpCode() == consts.CHI {
+					continue
+				}
+				if !rules.AllowWildcardMelds && jokers > 0 {
+					t.Errorf("种子 %d: 不能用赖子碰杠: %v", i, sc)
+				}
+				if normal[0] != sc.GetTile() {
+					t.Errorf("种子 %d: 用赖子碰杠时碰杠的应该是原来的牌: %v", i, sc)
+				}
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ type ShowCard struct {
 	opCode int       // 操作类型，对应吃、碰、杠对应的操作类型id
 	target int       // 明牌对象，吃、碰、杠的牌是谁打出来的
 	tiles  []card.ID // 关联的牌
+	tile   card.ID   // 碰杠的是哪张牌，用赖子补齐时 tiles[0] 可能是赖子，0 表示就是 tiles[0]
 	free   bool      // 是否付费，用于转弯杠，暂时用不上了
 	show   bool
 }
@@ -54,6 +55,9 @@ func (s *ShowCard) GetTiles() []card.ID {
 // GetTile 返回明牌中的牌是什么
 // 至于这个showCard是不是吃，需要外面的逻辑判断
 func (s *ShowCard) GetTile() card.ID {
+	if s.tile != 0 {
+		return s.tile
+	}
 	return s.tiles[0]
 }
 
@@ -67,6 +71,11 @@ func (s *ShowCard) IsFree() bool {
 	return s.free
 }
 
+// IsShow 是否亮出来，暗杠不亮
+func (s *ShowCard) IsShow() bool {
+	return s.show
+}
+
 // GetTilesLen 牌的数量
 func (s *ShowCard) GetTilesLen() int {
 	return len(s.tiles)
@@ -76,13 +85,15 @@ func (s *ShowCard) GetTilesLen() int {
 func (s *ShowCard) ModifyPongToKong(kongCode int, free bool) {
 	s.opCode = kongCode
 	s.free = free
-	s.tiles = append(s.tiles, s.tiles[0])
+	s.tiles = append(s.tiles, s.GetTile())
 	sort.Slice(s.tiles, func(i, j int) bool { return s.tiles[i] < s.tiles[j] })
 }
 
 // ModifyQiangKong 将kong设置为被抢的状态
+// 被抢的杠退回成碰
 func (s *ShowCard) ModifyQiangKong() {
-	s.tiles = append([]card.ID{}, s.tiles[0:s.GetTilesLen()-1]...)
+	s.opCode = consts.PENG
+	s.tiles = sliceDel(s.tiles, s.GetTile())
 }
 
 // IsPong 明牌是否是peng
@@ -92,5 +103,5 @@ func (s *ShowCard) IsPeng() bool {
 
 // IsPongTile 明牌是否是peng了这个牌
 func (s *ShowCard) IsPengTile(tile card.ID) bool {
-	return s.opCode == consts.PENG && s.tiles[0] == tile
+	return s.opCode == consts.PENG && s.GetTile() == tile
 }
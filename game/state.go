@@ -8,8 +8,11 @@ import (
 	"github.com/mikodream/mahjong/card"
 	"github.com/mikodream/mahjong/tile"
 	"github.com/mikodream/mahjong/ting"
+	"github.com/mikodream/mahjong/win"
 )
 
+const maxHints = 3 // 没有听牌时最多提示几种打法
+
 type State struct {
 	LastPlayer        *PlayerController
 	OriginallyPlayer  *PlayerController
@@ -19,8 +22,11 @@ type State struct {
 	CurrentPlayerHand []card.ID
 	PlayerSequence    []*PlayerController
 	PlayerShowCards   map[string][]*ShowCard
+	PlayerFlowers     map[string][]card.ID
 	SpecialPrivileges map[int][]int
 	CanWin            []*PlayerController
+	Wildcards         card.Wildcards // 赖子
+	WinOptions        win.Options    // 玩法规则允许胡的牌型，提示听牌时用，赖子以 Wildcards 为准
 	// Adding fields used in game.go ExtractState if needed, but better to fix game.go.
 	// game.go uses: ActivePlayer (matches CurrentPlayer?), LastPlayedTileFrom, AllPlayersID.
 	// I prefer adding them here if server relies on them.
@@ -31,11 +37,13 @@ type State struct {
 func (s State) String() string {
 	var lines []string
 	lines = append(lines, fmt.Sprintf("playedTiles:%s", tile.ToTileString(s.PlayedTiles)))
+	opts := s.WinOptions
+	opts.Wildcards = s.Wildcards
 	var playerStatuses []string
 
 	for _, player := range s.PlayerSequence {
 		playerStatus := fmt.Sprintf("%s:", player.Name())
-		if canTing, _ := ting.CanTing(player.Hand(), player.GetShowCardTiles()); canTing {
+		if canTing, _ := ting.CanTingWith(player.Hand(), player.GetShowCardTiles(), opts); canTing {
 			playerStatus += "(听)"
 		}
 		if showCards, ok := s.PlayerShowCards[player.Name()]; ok && len(showCards) > 0 {
@@ -43,6 +51,9 @@ func (s State) String() string {
 				playerStatus += fmt.Sprintf("%s ", showCard.String())
 			}
 		}
+		if flowers := s.PlayerFlowers[player.Name()]; len(flowers) > 0 {
+			playerStatus += fmt.Sprintf("[花]%s ", tile.ToTileString(flowers))
+		}
 		playerStatuses = append(playerStatuses, playerStatus)
 	}
 	drew := s.CurrentPlayerHand[len(s.CurrentPlayerHand)-1]
@@ -74,55 +85,28 @@ func (s State) String() string {
 
 	// If standingHand has 3n+2 tiles (e.g. 14), we need to discard one to Ting.
 	if len(standingHand)%3 == 2 {
-		// Identify which discards lead to Ting
-		type DiscardTing struct {
-			Discard card.ID
-			Ting    []card.ID
-		}
-		var suggestions []DiscardTing
-
-		// Use specific unique tiles to avoid redundant checks
-		checked := make(map[card.ID]bool)
-		for _, discard := range standingHand {
-			if checked[discard] {
-				continue
-			}
-			checked[discard] = true
-
-			// Create a temp hand with this tile removed
-			tempHand := make([]card.ID, len(standingHand)-1)
-			k := 0
-			removed := false
-			for _, v := range standingHand {
-				if !removed && v == discard {
-					removed = true
-					continue
-				}
-				tempHand[k] = v
-				k++
-			}
-
-			if ok, tings := ting.CanTing(tempHand, GetShowCardTiles(myShowCards)); ok {
-				suggestions = append(suggestions, DiscardTing{Discard: discard, Ting: tings})
+		// 按进张排序，听牌的打法在前，其次是向听数小、进张多的打法
+		analysis := ting.AnalyzeDiscardsWith(standingHand, GetShowCardTiles(myShowCards), s.visibleTiles(), opts)
+		var parts []string
+		for _, u := range analysis {
+			if u.Shanten != 0 {
+				break
 			}
+			parts = append(parts, fmt.Sprintf("打 %s 听 %s 剩 %d 张", tile.Tile(u.Discard), tile.ToTileString(u.Tiles), u.Total))
 		}
-
-		if len(suggestions) > 0 {
-			tingStatus = "(听)"
-			var parts []string
-			for _, s := range suggestions {
-				var tStrs []string
-				for _, t := range s.Ting {
-					tStrs = append(tStrs, tile.Tile(t).String())
-				}
-				parts = append(parts, fmt.Sprintf("打 %s 听 %s", tile.Tile(s.Discard), strings.Join(tStrs, " ")))
+		if len(parts) > 0 {
+			tingStatus = "(听) " + strings.Join(parts, "; ")
+		} else if len(analysis) > 0 && analysis[0].Shanten > 0 {
+			for i := 0; i < len(analysis) && i < maxHints && analysis[i].Shanten == analysis[0].Shanten; i++ {
+				u := analysis[i]
+				parts = append(parts, fmt.Sprintf("打 %s 进张 %d 张", tile.Tile(u.Discard), u.Total))
 			}
-			tingStatus += " " + strings.Join(parts, "; ")
+			tingStatus = fmt.Sprintf("(%d 向听) %s", analysis[0].Shanten, strings.Join(parts, "; "))
 		}
 
 	} else {
 		// Normal check (e.g. 13 tiles)
-		canTing, tingTiles = ting.CanTing(standingHand, GetShowCardTiles(myShowCards))
+		canTing, tingTiles = ting.CanTingWith(standingHand, GetShowCardTiles(myShowCards), opts)
 		if canTing {
 			tingStatus = "(听)"
 			tingStr := []string{}
@@ -163,6 +147,22 @@ func (s State) String() string {
 	return strings.Join(lines, "\n") + "\n"
 }
 
+// visibleTiles 当前玩家能看到的手牌以外的牌：牌池里的牌和其他玩家明着的吃碰杠
+func (s State) visibleTiles() []card.ID {
+	visible := append([]card.ID{}, s.PlayedTiles...)
+	for name, scs := range s.PlayerShowCards {
+		if s.CurrentPlayer != nil && name == s.CurrentPlayer.Name() {
+			continue
+		}
+		for _, sc := range scs {
+			if sc.IsShow() {
+				visible = append(visible, sc.tiles...)
+			}
+		}
+	}
+	return visible
+}
+
 func GetShowCardTiles(scs []*ShowCard) []card.ID {
 	ret := make([]card.ID, 0, len(scs)*4)
 	for _, t := range scs {
@@ -0,0 +1,33 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/win"
+)
+
+func TestStateStringWildcards(t *testing.T) {
+	player := NewPlayerController(&testPlayer{id: 0})
+	// 红中是赖子时 12 筒加一个赖子是一组，听 5 条单钓
+	hand := []card.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 21, 22, 15, card.MAHJONG_RED}
+	s := State{
+		CurrentPlayer:     player,
+		CurrentPlayerHand: hand,
+		WinOptions:        win.DefaultOptions,
+	}
+	if strings.Contains(s.String(), "(听)") {
+		t.Errorf("没有赖子时不应该听牌: %s", s.String())
+	}
+	s.Wildcards = card.Wildcards{card.MAHJONG_RED}
+	if !strings.Contains(s.String(), "(听)") {
+		t.Errorf("红中是赖子时应该听牌: %s", s.String())
+	}
+
+	// 打出赖子以外的牌才能听，提示里不应该是打红中
+	s.CurrentPlayerHand = append(hand, 31)
+	if str := s.String(); !strings.Contains(str, "打 东 听") {
+		t.Errorf("红中是赖子时应该提示打东: %s", str)
+	}
+}
@@ -0,0 +1,364 @@
+package hongkong
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+	"github.com/mikodream/mahjong/game"
+	"github.com/mikodream/mahjong/win"
+)
+
+// ErrNotWin 不是和牌牌型
+var ErrNotWin = errors.New("hongkong: not a winning hand")
+
+// winOptions 广东牌不算七对，十三幺爆棚
+var winOptions = win.Options{ThirteenOrphans: true}
+
+// Hand 食糊时的信息
+type Hand struct {
+	Tiles     []card.ID        // 手牌，包含食糊的那张牌，不包含明牌
+	Melds     []*game.ShowCard // 上、碰、杠的牌，包括暗杠
+	WinTile   card.ID          // 食糊的那张牌
+	SelfDrawn bool             // 自摸
+	Dealer    bool             // 庄家
+	SeatWind  card.ID          // 门风
+	RoundWind card.ID          // 圈风
+	Flowers   []card.ID        // 花牌
+	KongDraw  bool             // 杠后补牌自摸
+	LastDraw  bool             // 自摸牌墙的最后一张牌
+	RobKong   bool             // 抢杠
+	FirstDraw bool             // 第一巡没有人鸣牌时自摸，庄家是天胡，闲家是地胡
+}
+
+// Result 计番结果
+type Result struct {
+	Faans []Faan // 计入的番种，正花、箭刻可以计多次
+	Total int    // 总番数，不超过封顶
+	Limit bool   // 爆棚
+}
+
+// Count 计番，超过 maxFaan 或者爆棚时按 maxFaan 算
+func Count(h Hand, maxFaan int) (Result, error) {
+	meldTiles := make([]card.ID, 0, len(h.Melds)*4)
+	for _, m := range h.Melds {
+		meldTiles = append(meldTiles, m.GetTiles()...)
+	}
+	if !card.IDInSlice(h.WinTile, h.Tiles) || len(h.Tiles)+3*len(h.Melds) != 14 ||
+		!win.CanWinWith(h.Tiles, meldTiles, winOptions) {
+		return Result{}, ErrNotWin
+	}
+	c := newCounter(h)
+	faans := c.faans()
+	r := Result{}
+	for _, f := range faans {
+		if f.IsLimit() {
+			r.Limit = true
+		}
+	}
+	for _, f := range faans {
+		if r.Limit && !f.IsLimit() {
+			continue
+		}
+		r.Faans = append(r.Faans, f)
+		r.Total += f.Points()
+	}
+	if r.Limit || r.Total > maxFaan {
+		r.Total = maxFaan
+	}
+	return r, nil
+}
+
+// tileCounts 按 card.ID 下标的张数
+type tileCounts [card.MAHJONG_WHITE + 1]int
+
+func newTileCounts(tiles []card.ID) tileCounts {
+	var c tileCounts
+	for _, t := range tiles {
+		if int(t) < len(c) {
+			c[t]++
+		}
+	}
+	return c
+}
+
+// counter 一手牌的计番过程中共用的数据
+type counter struct {
+	Hand
+	hand    tileCounts // 手牌
+	all     tileCounts // 手牌和明牌，杠算三张
+	exposed bool       // 有上、碰、明杠
+	kongs   int
+	chows   int // 上的组数
+}
+
+func newCounter(h Hand) *counter {
+	c := &counter{Hand: h, hand: newTileCounts(h.Tiles)}
+	c.all = c.hand
+	for _, m := range h.Melds {
+		tiles := m.GetTiles()
+		switch m.GetOpCode() {
+		case consts.CHI:
+			c.chows++
+		case consts.GANG, consts.AN_GANG:
+			c.kongs++
+			tiles = tiles[:3]
+		}
+		if m.IsShow() && m.GetOpCode() != consts.AN_GANG {
+			c.exposed = true
+		}
+		for _, t := range tiles {
+			c.all[t]++
+		}
+	}
+	return c
+}
+
+func (c *counter) faans() []Faan {
+	var faans []Faan
+	if len(c.Melds) == 0 && win.IsThirteenOrphans(sortedIDs(c.Tiles)) {
+		faans = append(faans, ThirteenOrphans)
+	}
+	if c.FirstDraw && c.SelfDrawn && len(c.Melds) == 0 {
+		if c.Dealer {
+			faans = append(faans, HeavenlyHand)
+		} else {
+			faans = append(faans, EarthlyHand)
+		}
+	}
+	if c.SelfDrawn {
+		faans = append(faans, SelfDrawn)
+	}
+	if !c.exposed {
+		faans = append(faans, ConcealedHand)
+	}
+	faans = append(faans, c.flowerFaans()...)
+	switch {
+	case c.KongDraw && c.SelfDrawn:
+		faans = append(faans, KongDraw)
+	case c.LastDraw && c.SelfDrawn:
+		faans = append(faans, LastDraw)
+	}
+	if c.RobKong && !c.SelfDrawn {
+		faans = append(faans, RobKong)
+	}
+	if c.kongs == 4 {
+		faans = append(faans, FourKongs)
+	}
+
+	allPungs := c.chows == 0 && isAllPungs(c.hand)
+	switch {
+	case allPungs && !c.exposed && !(!c.SelfDrawn && c.hand[c.WinTile] == 3):
+		// 食别人打出的牌组成的刻子不算坎
+		faans = append(faans, AllConcealedPungs)
+	case allPungs:
+		faans = append(faans, AllPungs)
+	case c.chows == len(c.Melds) && isAllChows(c.hand):
+		faans = append(faans, AllChows)
+	}
+	faans = append(faans, c.honorFaans()...)
+	faans = append(faans, c.suitFaans()...)
+	return faans
+}
+
+// flowerFaans 无花、正花、一台花
+func (c *counter) flowerFaans() []Faan {
+	if len(c.Flowers) == 0 {
+		return []Faan{NoFlowers}
+	}
+	var faans []Faan
+	seat := seatIndex(c.SeatWind)
+	seasons, flowers := 0, 0
+	for _, f := range c.Flowers {
+		if f >= card.MAHJONG_SEASON1 && f <= card.MAHJONG_SEASON4 {
+			seasons++
+			if int(f-card.MAHJONG_SEASON1) == seat {
+				faans = append(faans, SeatFlower)
+			}
+		}
+		if f >= card.MAHJONG_FLOWER1 && f <= card.MAHJONG_FLOWER4 {
+			flowers++
+			if int(f-card.MAHJONG_FLOWER1) == seat {
+				faans = append(faans, SeatFlower)
+			}
+		}
+	}
+	if seasons == 4 {
+		faans = append(faans, FlowerSet)
+	}
+	if flowers == 4 {
+		faans = append(faans, FlowerSet)
+	}
+	return faans
+}
+
+// honorFaans 箭刻、风刻、三元、四喜，字牌只能组成刻子或者将，所以直接看张数
+func (c *counter) honorFaans() []Faan {
+	var faans []Faan
+	dragons, dragonPair := 0, false
+	for _, t := range []card.ID{card.MAHJONG_GREE, card.MAHJONG_RED, card.MAHJONG_WHITE} {
+		switch {
+		case c.all[t] >= 3:
+			dragons++
+		case c.all[t] == 2:
+			dragonPair = true
+		}
+	}
+	switch {
+	case dragons == 3:
+		faans = append(faans, BigDragons)
+	case dragons == 2 && dragonPair:
+		faans = append(faans, SmallDragons)
+	default:
+		for i := 0; i < dragons; i++ {
+			faans = append(faans, DragonPung)
+		}
+	}
+
+	winds, windPair := 0, false
+	for t := card.MAHJONG_EAST; t <= card.MAHJONG_WEST; t++ {
+		switch {
+		case c.all[t] >= 3:
+			winds++
+		case c.all[t] == 2:
+			windPair = true
+		}
+	}
+	switch {
+	case winds == 4:
+		faans = append(faans, BigWinds)
+	case winds == 3 && windPair:
+		faans = append(faans, SmallWinds)
+	default:
+		if c.all[c.SeatWind] >= 3 {
+			faans = append(faans, SeatWindPung)
+		}
+		if c.all[c.RoundWind] >= 3 {
+			faans = append(faans, RoundWindPung)
+		}
+	}
+	return faans
+}
+
+// suitFaans 混一色、清一色、字一色、混幺九、清幺九、九莲宝灯
+func (c *counter) suitFaans() []Faan {
+	suits := make(map[card.ID]bool)
+	honors, simples := false, false
+	for t, n := range c.all {
+		if n == 0 {
+			continue
+		}
+		id := card.ID(t)
+		switch {
+		case id.IsHonor():
+			honors = true
+		case id.Rank() != 1 && id.Rank() != 9:
+			simples = true
+			suits[id/10] = true
+		default:
+			suits[id/10] = true
+		}
+	}
+	var faans []Faan
+	switch {
+	case len(suits) == 0:
+		return append(faans, AllHonors)
+	case !simples && !honors:
+		faans = append(faans, AllTerminals)
+	case !simples:
+		faans = append(faans, MixedTerminals)
+	}
+	if len(suits) == 1 && honors {
+		faans = append(faans, HalfFlush)
+	}
+	if len(suits) == 1 && !honors {
+		faans = append(faans, FullFlush)
+		if len(c.Melds) == 0 && isNineGates(c.hand, c.WinTile/10*10) {
+			faans = append(faans, NineGates)
+		}
+	}
+	return faans
+}
+
+// seatIndex 门风对应的花牌序号：东 0、南 1、西 2、北 3
+func seatIndex(wind card.ID) int {
+	switch wind {
+	case card.MAHJONG_SOUTH:
+		return 1
+	case card.MAHJONG_WEST:
+		return 2
+	case card.MAHJONG_NORTH:
+		return 3
+	}
+	return 0
+}
+
+// isAllPungs 手牌能不能拆成一个将加若干刻子
+func isAllPungs(c tileCounts) bool {
+	pairs := 0
+	for _, n := range c {
+		switch n {
+		case 0, 3:
+		case 2:
+			pairs++
+		default:
+			return false
+		}
+	}
+	return pairs == 1
+}
+
+// isAllChows 手牌能不能拆成一个将加若干顺子
+func isAllChows(c tileCounts) bool {
+	for t := range c {
+		if c[t] < 2 {
+			continue
+		}
+		rest := c
+		rest[t] -= 2
+		if isChows(rest) {
+			return true
+		}
+	}
+	return false
+}
+
+// isChows 牌能不能全部拆成顺子，最小的一张一定是顺子的第一张
+func isChows(c tileCounts) bool {
+	for t := range c {
+		for c[t] > 0 {
+			id := card.ID(t)
+			if !id.IsSuit() || id.Rank() > 7 || c[t+1] == 0 || c[t+2] == 0 {
+				return false
+			}
+			c[t]--
+			c[t+1]--
+			c[t+2]--
+		}
+	}
+	return true
+}
+
+// isNineGates 九莲宝灯：同一门花色 1112345678999 加一张
+func isNineGates(c tileCounts, suit card.ID) bool {
+	total := 0
+	for r := card.ID(1); r <= 9; r++ {
+		need := 1
+		if r == 1 || r == 9 {
+			need = 3
+		}
+		if c[suit+r] < need {
+			return false
+		}
+		total += c[suit+r]
+	}
+	return total == 14
+}
+
+func sortedIDs(tiles []card.ID) []card.ID {
+	sorted := make([]card.ID, len(tiles))
+	copy(sorted, tiles)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
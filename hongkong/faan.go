@@ -0,0 +1,73 @@
+package hongkong
+
+// Faan 广东牌（香港老番）的番种
+type Faan int
+
+const (
+	SelfDrawn         Faan = iota // 自摸
+	ConcealedHand                 // 门前清
+	NoFlowers                     // 无花
+	SeatFlower                    // 正花，每张 1 番
+	FlowerSet                     // 一台花：春夏秋冬或者梅兰竹菊齐了
+	AllChows                      // 平糊
+	DragonPung                    // 箭刻，每组 1 番
+	SeatWindPung                  // 门风
+	RoundWindPung                 // 圈风
+	KongDraw                      // 杠上开花
+	LastDraw                      // 海底捞月
+	RobKong                       // 抢杠
+	MixedTerminals                // 混幺九
+	AllPungs                      // 对对糊
+	HalfFlush                     // 混一色
+	SmallDragons                  // 小三元
+	SmallWinds                    // 小四喜
+	FullFlush                     // 清一色
+	BigDragons                    // 大三元
+	AllConcealedPungs             // 坎坎胡
+
+	// 以下是爆棚的番种，直接按封顶算
+	BigWinds        // 大四喜
+	AllHonors       // 字一色
+	AllTerminals    // 清幺九
+	ThirteenOrphans // 十三幺
+	NineGates       // 九莲宝灯
+	FourKongs       // 十八罗汉
+	HeavenlyHand    // 天胡
+	EarthlyHand     // 地胡
+	FlowerHand      // 花胡：八仙过海、七抢一
+
+	faanCount
+)
+
+var faanNames = [faanCount]string{
+	"自摸", "门前清", "无花", "正花", "一台花", "平糊", "箭刻", "门风", "圈风",
+	"杠上开花", "海底捞月", "抢杠", "混幺九", "对对糊", "混一色", "小三元", "小四喜", "清一色", "大三元", "坎坎胡",
+	"大四喜", "字一色", "清幺九", "十三幺", "九莲宝灯", "十八罗汉", "天胡", "地胡", "花胡",
+}
+
+var faanPoints = [BigWinds]int{
+	SelfDrawn: 1, ConcealedHand: 1, NoFlowers: 1, SeatFlower: 1, FlowerSet: 2, AllChows: 1,
+	DragonPung: 1, SeatWindPung: 1, RoundWindPung: 1, KongDraw: 1, LastDraw: 1, RobKong: 1,
+	MixedTerminals: 1, AllPungs: 3, HalfFlush: 3, SmallDragons: 5, SmallWinds: 6, FullFlush: 7,
+	BigDragons: 8, AllConcealedPungs: 8,
+}
+
+func (f Faan) String() string {
+	if f < 0 || f >= faanCount {
+		return "未知番种"
+	}
+	return faanNames[f]
+}
+
+// IsLimit 是否爆棚的番种
+func (f Faan) IsLimit() bool {
+	return f >= BigWinds && f < faanCount
+}
+
+// Points 番数，爆棚的番种返回 0，由封顶番数决定
+func (f Faan) Points() int {
+	if f < 0 || f >= BigWinds {
+		return 0
+	}
+	return faanPoints[f]
+}
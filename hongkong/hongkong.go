@@ -0,0 +1,201 @@
+// Package hongkong 广东牌（香港老番）
+package hongkong
+
+import (
+	"context"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+	"github.com/mikodream/mahjong/game"
+	"github.com/mikodream/mahjong/win"
+)
+
+const (
+	defaultMinFaan = 3  // 默认三番起糊
+	defaultMaxFaan = 10 // 默认十番爆棚
+)
+
+// Rules 广东牌（香港老番）
+// 144 张牌（带花牌），起手 13 张，只能上家的牌，不算七对，够 MinFaan 番才能食糊
+// 结算时每份是 2 的番数次方：放铳的玩家付两份，另外两家各付一份；自摸时三家各付两份
+// 包牌：大三元的第三组箭刻、大四喜的第四组风刻、十二张落地的第四组明牌是谁打出来的，
+// 这次食糊的钱全部由他一个人付
+// 每局牌的庄家和门风记录在 Rules 中，所以每个 Game 要用一个新的 Rules
+type Rules struct {
+	game.BaseRules
+	MinFaan   int     // 起糊番数
+	MaxFaan   int     // 封顶番数，爆棚的牌按封顶算
+	RoundWind card.ID // 圈风，默认东
+
+	dealer *game.PlayerController
+	winds  map[int]card.ID
+	kongs  map[int]int // 玩家 ID => 上次出牌时杠的组数，用于判断杠上开花
+}
+
+// New 创建广东牌规则，三番起糊，十番爆棚
+func New() *Rules {
+	return &Rules{
+		BaseRules: game.BaseRules{Flowers: true},
+		MinFaan:   defaultMinFaan,
+		MaxFaan:   defaultMaxFaan,
+		RoundWind: card.MAHJONG_EAST,
+	}
+}
+
+func (r *Rules) WinOptions() win.Options {
+	return winOptions
+}
+
+// MatchRules 一场比赛每局用的规则：按 base 的设置创建，圈风由 game.Match 决定
+func MatchRules(base *Rules) game.RulesFactory {
+	return func(hand game.HandInfo, prev game.RuleSet) game.RuleSet {
+		return &Rules{
+			BaseRules: base.BaseRules,
+			MinFaan:   base.MinFaan,
+			MaxFaan:   base.MaxFaan,
+			RoundWind: hand.RoundWind,
+		}
+	}
+}
+
+// AfterDeal 确定庄家和门风
+func (r *Rules) AfterDeal(ctx context.Context, g *game.Game) error {
+	if r.RoundWind == 0 {
+		r.RoundWind = card.MAHJONG_EAST
+	}
+	r.dealer = g.Players().Peek()
+	r.winds = make(map[int]card.ID)
+	r.kongs = make(map[int]int)
+	winds := []card.ID{card.MAHJONG_EAST, card.MAHJONG_SOUTH, card.MAHJONG_WEST, card.MAHJONG_NORTH}
+	for i := 0; i < g.Players().Len() && i < len(winds); i++ {
+		r.winds[g.Players().After(r.dealer.ID(), i).ID()] = winds[i]
+	}
+	return nil
+}
+
+// AfterDiscard 记录出牌时杠的组数
+func (r *Rules) AfterDiscard(g *game.Game, player *game.PlayerController, tile card.ID) error {
+	r.kongs[player.ID()] = countKongs(player)
+	return nil
+}
+
+// AllowWin 够起糊番数才能食糊
+func (r *Rules) AllowWin(g *game.Game, player *game.PlayerController, hand []card.ID) bool {
+	selfDrawn := len(player.Hand())%3 == 2
+	winTile := hand[len(hand)-1]
+	res, err := Count(r.newHand(g, player, hand, winTile, selfDrawn, !selfDrawn && isKonged(g, winTile)), r.MaxFaan)
+	return err == nil && res.Total >= r.MinFaan
+}
+
+// Count 计算一次食糊的番数，花糊直接爆棚
+func (r *Rules) Count(g *game.Game, w game.Win) (Result, error) {
+	if w.Flower {
+		return Result{Faans: []Faan{FlowerHand}, Total: r.MaxFaan, Limit: true}, nil
+	}
+	return Count(r.newHand(g, w.Winner, w.Winner.Hand(), w.Tile, w.SelfDrawn, w.RobKong), r.MaxFaan)
+}
+
+func (r *Rules) newHand(g *game.Game, player *game.PlayerController, hand []card.ID, winTile card.ID, selfDrawn, robKong bool) Hand {
+	melds := 0
+	g.Players().ForEach(func(p *game.PlayerController) {
+		melds += len(p.GetShowCard())
+	})
+	return Hand{
+		Tiles:     hand,
+		Melds:     player.GetShowCard(),
+		WinTile:   winTile,
+		SelfDrawn: selfDrawn,
+		Dealer:    player == r.dealer,
+		SeatWind:  r.winds[player.ID()],
+		RoundWind: r.RoundWind,
+		Flowers:   player.Flowers(),
+		KongDraw:  selfDrawn && countKongs(player) > r.kongs[player.ID()],
+		LastDraw:  g.Deck().NoTiles(),
+		RobKong:   robKong,
+		FirstDraw: selfDrawn && len(g.Pile().Discards(player)) == 0 && melds == 0,
+	}
+}
+
+// Score 结算，见 Rules 的说明
+func (r *Rules) Score(g *game.Game, result *game.HandResult) (map[int]int, error) {
+	scores := make(map[int]int)
+	for _, w := range result.Wins {
+		res, err := r.Count(g, w)
+		if err != nil {
+			return nil, err
+		}
+		unit := 1 << res.Total
+		payments := make(map[int]int)
+		g.Players().ForEach(func(p *game.PlayerController) {
+			switch {
+			case p == w.Winner:
+			case w.SelfDrawn, p == w.Discarder:
+				payments[p.ID()] = 2 * unit
+			default:
+				payments[p.ID()] = unit
+			}
+		})
+		if liable, ok := Liable(w.Winner.GetShowCard()); ok && liable != w.Winner.ID() {
+			total := 0
+			for _, points := range payments {
+				total += points
+			}
+			payments = map[int]int{liable: total}
+		}
+		for id, points := range payments {
+			scores[id] -= points
+			scores[w.Winner.ID()] += points
+		}
+	}
+	return scores, nil
+}
+
+// Liable 包牌：按明牌的先后顺序，大三元的第三组箭刻、大四喜的第四组风刻、
+// 十二张落地的第四组明牌是谁打出来的，返回他的玩家 ID
+func Liable(melds []*game.ShowCard) (int, bool) {
+	dragons, winds, exposed := 0, 0, 0
+	for _, sc := range melds {
+		if !sc.IsShow() || sc.GetOpCode() == consts.AN_GANG {
+			continue
+		}
+		exposed++
+		t := sc.GetTile()
+		if sc.GetOpCode() != consts.CHI && t >= card.MAHJONG_GREE && t <= card.MAHJONG_WHITE {
+			dragons++
+			if dragons == 3 {
+				return sc.GetTarget(), true
+			}
+		}
+		if sc.GetOpCode() != consts.CHI && t >= card.MAHJONG_EAST && t <= card.MAHJONG_WEST {
+			winds++
+			if winds == 4 {
+				return sc.GetTarget(), true
+			}
+		}
+		if exposed == 4 {
+			return sc.GetTarget(), true
+		}
+	}
+	return 0, false
+}
+
+func countKongs(player *game.PlayerController) int {
+	n := 0
+	for _, sc := range player.GetShowCard() {
+		if sc.GetTilesLen() == 4 {
+			n++
+		}
+	}
+	return n
+}
+
+// isKonged 有没有玩家杠了这张牌，四张都杠出来了还能食的只能是抢杠
+func isKonged(g *game.Game, tile card.ID) bool {
+	konged := false
+	g.Players().ForEach(func(p *game.PlayerController) {
+		for _, sc := range p.GetShowCard() {
+			konged = konged || (sc.GetTilesLen() == 4 && sc.GetTile() == tile)
+		}
+	})
+	return konged
+}
@@ -0,0 +1,193 @@
+package hongkong
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+	"github.com/mikodream/mahjong/game"
+	"github.com/mikodream/mahjong/ting"
+)
+
+// testPlayer 能食就食，能碰就碰，能上就上，优先打出能听牌的牌
+type testPlayer struct {
+	id int
+}
+
+func (p *testPlayer) PlayerID() int {
+	return p.id
+}
+
+func (p *testPlayer) NickName() string {
+	return string(rune('A' + p.id))
+}
+
+func (p *testPlayer) Exchange(tiles []card.ID, gameState game.State) ([]card.ID, error) {
+	return tiles[:3], nil
+}
+
+func (p *testPlayer) Play(tiles []card.ID, gameState game.State) (card.ID, error) {
+	for discard := range ting.GetTingMap(tiles, nil) {
+		return discard, nil
+	}
+	best, bestScore := tiles[0], 100
+	for _, t := range tiles {
+		score := 0
+		for _, o := range tiles {
+			if d := int(o) - int(t); d >= -2 && d <= 2 {
+				score++
+			}
+		}
+		if score < bestScore {
+			best, bestScore = t, score
+		}
+	}
+	return best, nil
+}
+
+func (p *testPlayer) Take(tiles []card.ID, gameState game.State) (int, []card.ID, error) {
+	top := gameState.LastPlayedTile
+	for _, c := range gameState.CanWin {
+		if c.ID() == p.id {
+			return consts.WIN, []card.ID{top}, nil
+		}
+	}
+	for _, op := range gameState.SpecialPrivileges[p.id] {
+		if op == consts.PENG {
+			return consts.PENG, []card.ID{top, top, top}, nil
+		}
+		if op == consts.CHI {
+			chi := card.CanChiTiles(tiles[:len(tiles)-1], top)[0]
+			return consts.CHI, []card.ID{chi[0], chi[1], top}, nil
+		}
+	}
+	return 0, nil, nil
+}
+
+func (p *testPlayer) Act(tiles []card.ID, gameState game.State) (int, card.ID, error) {
+	for _, op := range gameState.SpecialPrivileges[p.id] {
+		if op == consts.WIN {
+			return consts.WIN, 0, nil
+		}
+	}
+	return 0, 0, nil
+}
+
+func TestCount(t *testing.T) {
+	pung := func(t card.ID, target int) *game.ShowCard {
+		return game.NewShowCard(consts.PENG, target, []card.ID{t, t, t}, true, false)
+	}
+	cases := []struct {
+		name  string
+		hand  Hand
+		faans []Faan
+		total int
+	}{
+		{
+			name:  "平糊门前清自摸，有正花",
+			hand:  Hand{Tiles: []card.ID{1, 2, 3, 4, 5, 6, 22, 23, 24, 16, 17, 18, 9, 9}, WinTile: 3, SelfDrawn: true, SeatWind: card.MAHJONG_SOUTH, Flowers: []card.ID{52, 63}},
+			faans: []Faan{SelfDrawn, ConcealedHand, SeatFlower, AllChows},
+			total: 4,
+		},
+		{
+			name: "混一色对对糊，箭刻",
+			hand: Hand{
+				Tiles: []card.ID{1, 1, 1, 5, 5, 41, 41, 41}, Melds: []*game.ShowCard{pung(9, 1), pung(card.MAHJONG_EAST, 2)},
+				WinTile: 5, SeatWind: card.MAHJONG_EAST, RoundWind: card.MAHJONG_EAST,
+			},
+			faans: []Faan{NoFlowers, AllPungs, DragonPung, SeatWindPung, RoundWindPung, HalfFlush},
+			total: 10,
+		},
+		{
+			name: "清一色，封顶",
+			hand: Hand{
+				Tiles: []card.ID{1, 1, 1, 2, 3, 4, 5, 6, 7, 8, 9, 9, 9, 5}, WinTile: 5,
+			},
+			faans: []Faan{NineGates},
+			total: 10,
+		},
+		{
+			name:  "坎坎胡：点糊的刻子不算坎",
+			hand:  Hand{Tiles: []card.ID{1, 1, 1, 5, 5, 5, 12, 12, 12, 27, 27, 27, 33, 33}, WinTile: 27, Flowers: []card.ID{53}},
+			faans: []Faan{ConcealedHand, AllPungs},
+			total: 4,
+		},
+		{
+			name:  "十三幺",
+			hand:  Hand{Tiles: []card.ID{1, 9, 11, 19, 21, 29, 31, 32, 33, 34, 41, 42, 43, 43}, WinTile: 1},
+			faans: []Faan{ThirteenOrphans},
+			total: 10,
+		},
+	}
+	for _, c := range cases {
+		r, err := Count(c.hand, defaultMaxFaan)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(r.Faans, c.faans) || r.Total != c.total {
+			t.Errorf("%s: 期望 %v %d 番, 实际 %v %d 番", c.name, c.faans, c.total, r.Faans, r.Total)
+		}
+	}
+
+	if _, err := Count(Hand{Tiles: []card.ID{1, 2, 4, 11, 12, 13, 21, 22, 23, 29, 29, 31, 31, 31}, WinTile: 4}, defaultMaxFaan); err != ErrNotWin {
+		t.Errorf("不是和牌牌型应该返回 ErrNotWin, 实际 %v", err)
+	}
+}
+
+func TestLiable(t *testing.T) {
+	meld := func(op int, tiles []card.ID, target int) *game.ShowCard {
+		return game.NewShowCard(op, target, tiles, true, false)
+	}
+	dragons := []*game.ShowCard{
+		meld(consts.PENG, []card.ID{41, 41, 41}, 1),
+		meld(consts.PENG, []card.ID{42, 42, 42}, 2),
+		meld(consts.PENG, []card.ID{43, 43, 43}, 3),
+	}
+	if id, ok := Liable(dragons); !ok || id != 3 {
+		t.Errorf("大三元应该由打出第三组箭刻的玩家包牌: %d %v", id, ok)
+	}
+	if _, ok := Liable(dragons[:2]); ok {
+		t.Errorf("两组箭刻不用包牌")
+	}
+	twelve := []*game.ShowCard{
+		meld(consts.CHI, []card.ID{1, 2, 3}, 1),
+		meld(consts.PENG, []card.ID{5, 5, 5}, 2),
+		game.NewShowCard(consts.GANG, 0, []card.ID{7, 7, 7, 7}, false, false),
+		meld(consts.CHI, []card.ID{11, 12, 13}, 1),
+		meld(consts.PENG, []card.ID{19, 19, 19}, 3),
+	}
+	if id, ok := Liable(twelve); !ok || id != 3 {
+		t.Errorf("十二张落地应该由打出第四组明牌的玩家包牌，暗杠不算: %d %v", id, ok)
+	}
+}
+
+func TestRun(t *testing.T) {
+	for i := 0; i < 30; i++ {
+		players := make([]game.Player, 0, 4)
+		for id := 0; id < 4; id++ {
+			players = append(players, &testPlayer{id: id})
+		}
+		rules := New()
+		g := game.New(players, game.WithRuleSet(rules))
+		result, err := g.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+		total := 0
+		for _, s := range result.Scores {
+			total += s
+		}
+		if total != 0 {
+			t.Errorf("输赢分加起来应该为 0: %v", result.Scores)
+		}
+		for _, w := range result.Wins {
+			res, err := rules.Count(g, w)
+			if err != nil || res.Total < rules.MinFaan {
+				t.Errorf("%s 不够番食糊: %v %+v %v", w.Winner.Name(), w.Winner.Hand(), res, err)
+			}
+		}
+	}
+}
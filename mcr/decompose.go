@@ -0,0 +1,150 @@
+package mcr
+
+import (
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/win"
+)
+
+// setKind 拆牌后每一组牌的类型
+type setKind int
+
+const (
+	chowSet    setKind = iota // 顺子
+	pungSet                   // 刻子
+	kongSet                   // 杠
+	pairSet                   // 将
+	knittedSet                // 组合龙的九张牌
+)
+
+// set 拆牌后的一组牌
+type set struct {
+	kind      setKind
+	tile      card.ID // 顺子是最小的一张，组合龙是 knittedPatterns 的下标
+	concealed bool    // 是否在手里，明牌中只有暗杠算
+}
+
+// tiles 这组牌包含的牌
+func (s set) tiles() []card.ID {
+	switch s.kind {
+	case chowSet:
+		return []card.ID{s.tile, s.tile + 1, s.tile + 2}
+	case pungSet:
+		return []card.ID{s.tile, s.tile, s.tile}
+	case kongSet:
+		return []card.ID{s.tile, s.tile, s.tile, s.tile}
+	case pairSet:
+		return []card.ID{s.tile, s.tile}
+	case knittedSet:
+		return knittedPatterns[s.tile][:]
+	}
+	return nil
+}
+
+// contains 这组牌里有没有 t
+func (s set) contains(t card.ID) bool {
+	return card.IDInSlice(t, s.tiles())
+}
+
+// tileCounts 按 card.ID 下标的张数
+type tileCounts [card.MAHJONG_WHITE + 1]int
+
+// newTileCounts 统计牌的张数，有花牌或者不存在的牌时返回 false
+func newTileCounts(tiles []card.ID) (tileCounts, bool) {
+	var c tileCounts
+	for _, t := range tiles {
+		if t <= 0 || int(t) >= len(c) || t.Rank() == 0 {
+			return c, false
+		}
+		c[t]++
+	}
+	return c, true
+}
+
+// newSet 把 win.Decompose 拆出来的一组牌转换成算番用的 set
+func newSet(m win.Meld) set {
+	s := set{kind: pairSet, tile: m.Tile, concealed: m.Concealed}
+	switch m.Kind {
+	case win.Chow:
+		s.kind = chowSet
+	case win.Pung:
+		s.kind = pungSet
+	case win.Kong:
+		s.kind = kongSet
+	}
+	return s
+}
+
+func newSets(melds []win.Meld) []set {
+	sets := make([]set, len(melds))
+	for i, m := range melds {
+		sets[i] = newSet(m)
+	}
+	return sets
+}
+
+// knittedPatterns 组合龙：三门花色分别是 147、258、369，共 6 种
+var knittedPatterns = func() [][9]card.ID {
+	var patterns [][9]card.ID
+	for _, suits := range [][3]card.ID{{0, 10, 20}, {0, 20, 10}, {10, 0, 20}, {10, 20, 0}, {20, 0, 10}, {20, 10, 0}} {
+		var p [9]card.ID
+		for i, suit := range suits {
+			for j := 0; j < 3; j++ {
+				p[i*3+j] = suit + card.ID(i+1+j*3)
+			}
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns
+}()
+
+// decomposeKnitted 组合龙加一个顺子或刻子和一个将的所有拆法，组合龙在第一组，明牌在最后
+func decomposeKnitted(tiles []card.ID, melds []win.Meld) [][]set {
+	var ret [][]set
+	for i, p := range knittedPatterns {
+		rest, ok := removeTiles(tiles, p[:])
+		if !ok {
+			continue
+		}
+		for _, d := range win.Decompose(rest, melds, 0, win.Options{}) {
+			knitted := set{kind: knittedSet, tile: card.ID(i), concealed: true}
+			ret = append(ret, append([]set{knitted}, newSets(d.Melds)...))
+		}
+	}
+	return ret
+}
+
+// removeTiles 从 tiles 中各去掉一张 remove 里的牌，有的牌不在 tiles 里时返回 false
+func removeTiles(tiles, remove []card.ID) ([]card.ID, bool) {
+	rest := append([]card.ID{}, tiles...)
+	for _, r := range remove {
+		found := false
+		for i, t := range rest {
+			if t == r {
+				rest = append(rest[:i], rest[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return rest, true
+}
+
+// knittedPattern 全不靠：数牌都在同一种组合龙里，返回组合龙的下标
+func knittedPattern(tiles []card.ID) (int, bool) {
+	for i, p := range knittedPatterns {
+		ok := true
+		for _, t := range tiles {
+			if t.IsSuit() && !card.IDInSlice(t, p[:]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,216 @@
+package mcr
+
+// Fan 国标麻将的番种
+type Fan int
+
+// 81 个番种，按番数从高到低排列
+const (
+	// 88 番
+	BigFourWinds      Fan = iota // 大四喜
+	BigThreeDragons              // 大三元
+	AllGreen                     // 绿一色
+	NineGates                    // 九莲宝灯
+	FourKongs                    // 四杠
+	SevenShiftedPairs            // 连七对
+	ThirteenOrphans              // 十三幺
+
+	// 64 番
+	AllTerminals       // 清幺九
+	LittleFourWinds    // 小四喜
+	LittleThreeDragons // 小三元
+	AllHonors          // 字一色
+	FourConcealedPungs // 四暗刻
+	PureTerminalChows  // 一色双龙会
+
+	// 48 番
+	QuadrupleChow        // 一色四同顺
+	FourPureShiftedPungs // 一色四节高
+
+	// 32 番
+	FourPureShiftedChows  // 一色四步高
+	ThreeKongs            // 三杠
+	AllTerminalsAndHonors // 混幺九
+
+	// 24 番
+	SevenPairs                   // 七对
+	GreaterHonorsAndKnittedTiles // 七星不靠
+	AllEvenPungs                 // 全双刻
+	FullFlush                    // 清一色
+	PureTripleChow               // 一色三同顺
+	PureShiftedPungs             // 一色三节高
+	UpperTiles                   // 全大
+	MiddleTiles                  // 全中
+	LowerTiles                   // 全小
+
+	// 16 番
+	PureStraight             // 清龙
+	ThreeSuitedTerminalChows // 三色双龙会
+	PureShiftedChows         // 一色三步高
+	AllFives                 // 全带五
+	TriplePung               // 三同刻
+	ThreeConcealedPungs      // 三暗刻
+
+	// 12 番
+	LesserHonorsAndKnittedTiles // 全不靠
+	KnittedStraight             // 组合龙
+	UpperFour                   // 大于五
+	LowerFour                   // 小于五
+	BigThreeWinds               // 三风刻
+
+	// 8 番
+	MixedStraight          // 花龙
+	ReversibleTiles        // 推不倒
+	MixedTripleChow        // 三色三同顺
+	MixedShiftedPungs      // 三色三节高
+	ChickenHand            // 无番和
+	LastTileDraw           // 妙手回春
+	LastTileClaim          // 海底捞月
+	OutWithReplacementTile // 杠上开花
+	RobbingTheKong         // 抢杠和
+
+	// 6 番
+	AllPungs          // 碰碰和
+	HalfFlush         // 混一色
+	MixedShiftedChows // 三色三步高
+	AllTypes          // 五门齐
+	MeldedHand        // 全求人
+	TwoConcealedKongs // 双暗杠
+	TwoDragonPungs    // 双箭刻
+
+	// 4 番
+	OutsideHand        // 全带幺
+	FullyConcealedHand // 不求人
+	TwoMeldedKongs     // 双明杠
+	LastTile           // 和绝张
+
+	// 2 番
+	DragonPung        // 箭刻
+	PrevalentWind     // 圈风刻
+	SeatWind          // 门风刻
+	ConcealedHand     // 门前清
+	AllChows          // 平和
+	TileHog           // 四归一
+	DoublePung        // 双同刻
+	TwoConcealedPungs // 双暗刻
+	ConcealedKong     // 暗杠
+	AllSimples        // 断幺
+
+	// 1 番
+	PureDoubleChow          // 一般高
+	MixedDoubleChow         // 喜相逢
+	ShortStraight           // 连六
+	TwoTerminalChows        // 老少副
+	PungOfTerminalsOrHonors // 幺九刻
+	MeldedKong              // 明杠
+	OneVoidedSuit           // 缺一门
+	NoHonors                // 无字
+	EdgeWait                // 边张
+	ClosedWait              // 坎张
+	SingleWait              // 单钓将
+	SelfDrawn               // 自摸
+	FlowerTiles             // 花牌
+
+	fanCount
+)
+
+var fanNames = [fanCount]string{
+	"大四喜", "大三元", "绿一色", "九莲宝灯", "四杠", "连七对", "十三幺",
+	"清幺九", "小四喜", "小三元", "字一色", "四暗刻", "一色双龙会",
+	"一色四同顺", "一色四节高",
+	"一色四步高", "三杠", "混幺九",
+	"七对", "七星不靠", "全双刻", "清一色", "一色三同顺", "一色三节高", "全大", "全中", "全小",
+	"清龙", "三色双龙会", "一色三步高", "全带五", "三同刻", "三暗刻",
+	"全不靠", "组合龙", "大于五", "小于五", "三风刻",
+	"花龙", "推不倒", "三色三同顺", "三色三节高", "无番和", "妙手回春", "海底捞月", "杠上开花", "抢杠和",
+	"碰碰和", "混一色", "三色三步高", "五门齐", "全求人", "双暗杠", "双箭刻",
+	"全带幺", "不求人", "双明杠", "和绝张",
+	"箭刻", "圈风刻", "门风刻", "门前清", "平和", "四归一", "双同刻", "双暗刻", "暗杠", "断幺",
+	"一般高", "喜相逢", "连六", "老少副", "幺九刻", "明杠", "缺一门", "无字", "边张", "坎张", "单钓将", "自摸", "花牌",
+}
+
+func (f Fan) String() string {
+	return fanNames[f]
+}
+
+// Points 番种的番数
+func (f Fan) Points() int {
+	switch {
+	case f <= ThirteenOrphans:
+		return 88
+	case f <= PureTerminalChows:
+		return 64
+	case f <= FourPureShiftedPungs:
+		return 48
+	case f <= AllTerminalsAndHonors:
+		return 32
+	case f <= LowerTiles:
+		return 24
+	case f <= ThreeConcealedPungs:
+		return 16
+	case f <= BigThreeWinds:
+		return 12
+	case f <= RobbingTheKong:
+		return 8
+	case f <= TwoDragonPungs:
+		return 6
+	case f <= LastTile:
+		return 4
+	case f <= AllSimples:
+		return 2
+	}
+	return 1
+}
+
+// excludes 不计原则：计了左边的番种就不再计右边的番种
+// 组合关系已经在判断番种时处理的（比如大四喜不会再判断三风刻）不列在这里
+var excludes = map[Fan][]Fan{
+	BigFourWinds:      {AllPungs},
+	AllGreen:          {HalfFlush},
+	NineGates:         {FullFlush, ConcealedHand, FullyConcealedHand, PungOfTerminalsOrHonors, NoHonors},
+	FourKongs:         {AllPungs, SingleWait},
+	SevenShiftedPairs: {SevenPairs, FullFlush, ConcealedHand, FullyConcealedHand, SingleWait, NoHonors},
+	ThirteenOrphans:   {AllTypes, AllTerminalsAndHonors, ConcealedHand, FullyConcealedHand, SingleWait},
+
+	AllTerminals:       {AllTerminalsAndHonors, AllPungs, OutsideHand, PungOfTerminalsOrHonors, DoublePung, NoHonors},
+	AllHonors:          {AllTerminalsAndHonors, AllPungs, OutsideHand, PungOfTerminalsOrHonors},
+	FourConcealedPungs: {AllPungs, ConcealedHand, FullyConcealedHand},
+	PureTerminalChows:  {FullFlush, AllChows, PureDoubleChow, TwoTerminalChows, NoHonors},
+
+	QuadrupleChow:        {PureTripleChow, PureShiftedPungs, PureDoubleChow, TileHog},
+	FourPureShiftedPungs: {PureShiftedPungs, PureTripleChow, AllPungs},
+
+	FourPureShiftedChows:  {PureShiftedChows, ShortStraight, TwoTerminalChows},
+	AllTerminalsAndHonors: {AllPungs, OutsideHand, PungOfTerminalsOrHonors},
+
+	SevenPairs:                   {ConcealedHand, FullyConcealedHand, SingleWait},
+	GreaterHonorsAndKnittedTiles: {LesserHonorsAndKnittedTiles, AllTypes, ConcealedHand, FullyConcealedHand, SingleWait},
+	AllEvenPungs:                 {AllPungs, AllSimples, NoHonors},
+	FullFlush:                    {NoHonors},
+	PureTripleChow:               {PureShiftedPungs, PureDoubleChow},
+	PureShiftedPungs:             {PureTripleChow},
+	UpperTiles:                   {UpperFour, NoHonors},
+	MiddleTiles:                  {AllSimples, NoHonors},
+	LowerTiles:                   {LowerFour, NoHonors},
+
+	PureStraight:             {ShortStraight, TwoTerminalChows},
+	ThreeSuitedTerminalChows: {AllChows, MixedDoubleChow, TwoTerminalChows, NoHonors},
+	AllFives:                 {AllSimples, NoHonors},
+
+	LesserHonorsAndKnittedTiles: {AllTypes, ConcealedHand, FullyConcealedHand, SingleWait},
+	UpperFour:                   {NoHonors},
+	LowerFour:                   {NoHonors},
+
+	ReversibleTiles:        {OneVoidedSuit},
+	MixedTripleChow:        {MixedDoubleChow},
+	LastTileDraw:           {SelfDrawn},
+	OutWithReplacementTile: {SelfDrawn},
+	RobbingTheKong:         {LastTile},
+
+	MeldedHand:        {SingleWait},
+	TwoConcealedKongs: {TwoConcealedPungs},
+
+	FullyConcealedHand: {ConcealedHand, SelfDrawn},
+
+	AllChows:   {NoHonors},
+	AllSimples: {NoHonors},
+}
@@ -0,0 +1,587 @@
+package mcr
+
+import (
+	"sort"
+
+	"github.com/mikodream/mahjong/card"
+)
+
+// fans 一种拆法计入的所有番种，不计原则在 newResult 中处理
+func (e *evaluator) fans(c candidate) []Fan {
+	var fans []Fan
+	if c.special >= 0 {
+		fans = append(fans, c.special)
+		if len(c.sets) > 0 {
+			fans = append(fans, KnittedStraight)
+		}
+	} else {
+		fans = append(fans, e.setFans(c)...)
+	}
+	fans = append(fans, e.tileFans()...)
+	fans = append(fans, e.situationFans()...)
+	return fans
+}
+
+// setFans 一般牌型按拆出来的顺子、刻子计的番种
+func (e *evaluator) setFans(c candidate) []Fan {
+	var fans []Fan
+	var chows, pungs []card.ID
+	var pair card.ID
+	knitted := false
+	kongs, concealedKongs, concealedPungs := 0, 0, 0
+	for _, s := range c.sets {
+		switch s.kind {
+		case chowSet:
+			chows = append(chows, s.tile)
+		case pungSet, kongSet:
+			pungs = append(pungs, s.tile)
+			if s.concealed {
+				concealedPungs++
+			}
+			if s.kind == kongSet {
+				kongs++
+				if s.concealed {
+					concealedKongs++
+				}
+			}
+		case pairSet:
+			pair = s.tile
+		case knittedSet:
+			knitted = true
+			fans = append(fans, KnittedStraight)
+		}
+	}
+
+	// 风刻、箭刻
+	var winds, dragons []card.ID
+	for _, t := range pungs {
+		if isWind(t) {
+			winds = append(winds, t)
+		} else if isDragon(t) {
+			dragons = append(dragons, t)
+		} else if isTerminal(t) {
+			fans = append(fans, PungOfTerminalsOrHonors)
+		}
+	}
+	switch {
+	case len(winds) == 4:
+		fans = append(fans, BigFourWinds)
+	case len(winds) == 3 && isWind(pair):
+		fans = append(fans, LittleFourWinds)
+	case len(winds) == 3:
+		fans = append(fans, BigThreeWinds)
+	}
+	if len(winds) < 4 {
+		for _, t := range winds {
+			switch {
+			case t == e.PrevalentWind && t == e.SeatWind:
+				fans = append(fans, PrevalentWind, SeatWind)
+			case t == e.PrevalentWind:
+				fans = append(fans, PrevalentWind)
+			case t == e.SeatWind:
+				fans = append(fans, SeatWind)
+			case len(winds) < 3:
+				fans = append(fans, PungOfTerminalsOrHonors)
+			}
+		}
+	}
+	switch {
+	case len(dragons) == 3:
+		fans = append(fans, BigThreeDragons)
+	case len(dragons) == 2 && isDragon(pair):
+		fans = append(fans, LittleThreeDragons)
+	case len(dragons) == 2:
+		fans = append(fans, TwoDragonPungs)
+	case len(dragons) == 1:
+		fans = append(fans, DragonPung)
+	}
+
+	// 杠
+	switch kongs {
+	case 4:
+		fans = append(fans, FourKongs)
+	case 3:
+		fans = append(fans, ThreeKongs)
+	case 2:
+		switch concealedKongs {
+		case 2:
+			fans = append(fans, TwoConcealedKongs)
+		case 1:
+			fans = append(fans, ConcealedKong, MeldedKong)
+		default:
+			fans = append(fans, TwoMeldedKongs)
+		}
+	case 1:
+		if concealedKongs == 1 {
+			fans = append(fans, ConcealedKong)
+		} else {
+			fans = append(fans, MeldedKong)
+		}
+	}
+
+	// 暗刻
+	switch concealedPungs {
+	case 4:
+		fans = append(fans, FourConcealedPungs)
+	case 3:
+		fans = append(fans, ThreeConcealedPungs)
+	case 2:
+		fans = append(fans, TwoConcealedPungs)
+	}
+
+	if len(pungs) == 4 {
+		fans = append(fans, AllPungs)
+	}
+	if (len(chows) == 4 || (knitted && len(chows) == 1)) && pair.IsSuit() {
+		fans = append(fans, AllChows)
+	}
+	fans = append(fans, pungFans(pungs)...)
+	fans = append(fans, chowFans(chows, pair)...)
+	fans = append(fans, e.outsideFans(c.sets)...)
+	if len(e.waits) == 1 && c.winSet >= 0 {
+		fans = append(fans, waitFan(c.sets[c.winSet], e.WinTile)...)
+	}
+	if e.opened == 4 && !e.SelfDrawn {
+		fans = append(fans, MeldedHand)
+	}
+	return fans
+}
+
+// pungFans 刻子之间组合的番种：同刻、节高
+func pungFans(pungs []card.ID) []Fan {
+	var fans []Fan
+	suited := make(map[card.ID]bool)
+	ranks := make(map[int]int)
+	for _, t := range pungs {
+		if t.IsSuit() {
+			suited[t] = true
+			ranks[t.Rank()]++
+		}
+	}
+	for _, n := range ranks {
+		switch n {
+		case 3:
+			fans = append(fans, TriplePung)
+		case 2:
+			fans = append(fans, DoublePung)
+		}
+	}
+
+	// 一色节高：同一门花色最长的连续刻子
+	longest := 0
+	for t := range suited {
+		n := 1
+		for suited[t+card.ID(n)] && (t+card.ID(n)).Rank() != 0 {
+			n++
+		}
+		if n > longest {
+			longest = n
+		}
+	}
+	switch {
+	case longest >= 4:
+		fans = append(fans, FourPureShiftedPungs)
+	case longest == 3:
+		fans = append(fans, PureShiftedPungs)
+	default:
+		// 三色三节高：三门花色的刻子点数依次递增
+		for r := 1; r <= 7; r++ {
+			if hasMixed(suited, [3]int{r, r + 1, r + 2}) {
+				fans = append(fans, MixedShiftedPungs)
+				break
+			}
+		}
+	}
+	return fans
+}
+
+// hasMixed 三门花色各有一组，点数分别是 ranks 的某种排列
+func hasMixed(sets map[card.ID]bool, ranks [3]int) bool {
+	for _, suits := range [][3]card.ID{{0, 10, 20}, {0, 20, 10}, {10, 0, 20}, {10, 20, 0}, {20, 0, 10}, {20, 10, 0}} {
+		if sets[suits[0]+card.ID(ranks[0])] && sets[suits[1]+card.ID(ranks[1])] && sets[suits[2]+card.ID(ranks[2])] {
+			return true
+		}
+	}
+	return false
+}
+
+// chowFans 顺子之间组合的番种
+// 先找四个顺子的番种，再找三个顺子的番种，剩下的两两组合，
+// 按套算一次原则，没组合过的顺子只能和组合过的顺子再组合一次
+func chowFans(chows []card.ID, pair card.ID) []Fan {
+	if f, ok := fourChowFan(chows, pair); ok {
+		return []Fan{f}
+	}
+
+	// 三个顺子的番种取番数最高的，组成它的三个顺子当作一组
+	group := make([]int, len(chows))
+	for i := range group {
+		group[i] = i
+	}
+	var fans []Fan
+	if f, skip, ok := threeChowFan(chows); ok {
+		fans = append(fans, f)
+		for i := range group {
+			if i != skip {
+				group[i] = -1
+			}
+		}
+	}
+
+	// 两两组合，每组合一次就把两组合并，已经在同一组的不再组合
+	for i := 0; i < len(chows); i++ {
+		for j := i + 1; j < len(chows); j++ {
+			if group[i] == group[j] {
+				continue
+			}
+			if f, ok := twoChowFan(chows[i], chows[j]); ok {
+				fans = append(fans, f)
+				from, to := group[j], group[i]
+				for k := range group {
+					if group[k] == from {
+						group[k] = to
+					}
+				}
+			}
+		}
+	}
+	return fans
+}
+
+// fourChowFan 四个顺子的番种
+func fourChowFan(chows []card.ID, pair card.ID) (Fan, bool) {
+	if len(chows) != 4 {
+		return 0, false
+	}
+	sorted := sortedIDs(chows)
+	a, b, c, d := sorted[0], sorted[1], sorted[2], sorted[3]
+	if sameSuit(a, d) {
+		switch {
+		case a == d:
+			return QuadrupleChow, true
+		case b-a == c-b && c-b == d-c && (b-a == 1 || b-a == 2):
+			return FourPureShiftedChows, true
+		case a.Rank() == 1 && a == b && c.Rank() == 7 && c == d && pair == a+4:
+			return PureTerminalChows, true
+		}
+	}
+	// 三色双龙会：两门花色的老少副，第三门花色的 5 做将
+	if pair.IsSuit() && pair.Rank() == 5 && isTerminalChows(sorted) {
+		suits := map[card.ID]bool{pair / 10: true}
+		for _, t := range sorted {
+			suits[t/10] = true
+		}
+		if len(suits) == 3 {
+			return ThreeSuitedTerminalChows, true
+		}
+	}
+	return 0, false
+}
+
+// isTerminalChows 四个顺子正好是两门花色的 123 和 789
+func isTerminalChows(sorted []card.ID) bool {
+	return sorted[0].Rank() == 1 && sorted[1] == sorted[0]+6 &&
+		sorted[2].Rank() == 1 && sorted[3] == sorted[2]+6 && !sameSuit(sorted[0], sorted[2])
+}
+
+// threeChowFan 三个顺子组成的番数最高的番种，有四个顺子时返回没用到的顺子的下标
+func threeChowFan(chows []card.ID) (Fan, int, bool) {
+	if len(chows) == 3 {
+		f, ok := chowTripleFan(chows)
+		return f, -1, ok
+	}
+	best, skip, found := Fan(0), -1, false
+	for i := 0; i < len(chows) && len(chows) == 4; i++ {
+		three := make([]card.ID, 0, 3)
+		three = append(three, chows[:i]...)
+		three = append(three, chows[i+1:]...)
+		if f, ok := chowTripleFan(three); ok && (!found || f < best) {
+			best, skip, found = f, i, true
+		}
+	}
+	return best, skip, found
+}
+
+// chowTripleFan 三个顺子的番种
+func chowTripleFan(chows []card.ID) (Fan, bool) {
+	sorted := sortedIDs(chows)
+	a, b, c := sorted[0], sorted[1], sorted[2]
+	if sameSuit(a, c) {
+		switch {
+		case a == c:
+			return PureTripleChow, true
+		case a.Rank() == 1 && b.Rank() == 4 && c.Rank() == 7:
+			return PureStraight, true
+		case b-a == c-b && (b-a == 1 || b-a == 2):
+			return PureShiftedChows, true
+		}
+		return 0, false
+	}
+	suits := map[card.ID]bool{a / 10: true, b / 10: true, c / 10: true}
+	if len(suits) != 3 {
+		return 0, false
+	}
+	set := map[card.ID]bool{a: true, b: true, c: true}
+	switch {
+	case a.Rank() == b.Rank() && b.Rank() == c.Rank():
+		return MixedTripleChow, true
+	case hasMixed(set, [3]int{1, 4, 7}):
+		return MixedStraight, true
+	}
+	for r := 1; r <= 5; r++ {
+		if hasMixed(set, [3]int{r, r + 1, r + 2}) {
+			return MixedShiftedChows, true
+		}
+	}
+	return 0, false
+}
+
+// twoChowFan 两个顺子的番种
+func twoChowFan(a, b card.ID) (Fan, bool) {
+	if a > b {
+		a, b = b, a
+	}
+	switch {
+	case a == b:
+		return PureDoubleChow, true
+	case a.Rank() == b.Rank():
+		return MixedDoubleChow, true
+	case sameSuit(a, b) && b-a == 3:
+		return ShortStraight, true
+	case sameSuit(a, b) && a.Rank() == 1 && b.Rank() == 7:
+		return TwoTerminalChows, true
+	}
+	return 0, false
+}
+
+// outsideFans 每组牌都要满足条件的番种：全带幺、全带五、全双刻
+func (e *evaluator) outsideFans(sets []set) []Fan {
+	var fans []Fan
+	outside, fives, even := true, true, true
+	for _, s := range sets {
+		tiles := s.tiles()
+		switch s.kind {
+		case knittedSet:
+			outside, fives, even = false, false, false
+			continue
+		case chowSet:
+			even = false
+		}
+		hasTerminal, hasFive := false, false
+		for _, t := range tiles {
+			hasTerminal = hasTerminal || isTerminal(t) || t.IsHonor()
+			hasFive = hasFive || (t.IsSuit() && t.Rank() == 5)
+		}
+		outside = outside && hasTerminal
+		fives = fives && hasFive
+		even = even && s.tile.IsSuit() && s.tile.Rank()%2 == 0
+	}
+	if outside {
+		fans = append(fans, OutsideHand)
+	}
+	if fives {
+		fans = append(fans, AllFives)
+	}
+	if even {
+		fans = append(fans, AllEvenPungs)
+	}
+	return fans
+}
+
+// waitFan 边张、坎张、单钓将
+func waitFan(s set, winTile card.ID) []Fan {
+	switch s.kind {
+	case pairSet:
+		return []Fan{SingleWait}
+	case chowSet:
+		switch {
+		case winTile == s.tile+1:
+			return []Fan{ClosedWait}
+		case winTile == s.tile+2 && s.tile.Rank() == 1, winTile == s.tile && s.tile.Rank() == 7:
+			return []Fan{EdgeWait}
+		}
+	}
+	return nil
+}
+
+// tileFans 按所有的牌（手牌和明牌）计的番种
+func (e *evaluator) tileFans() []Fan {
+	var fans []Fan
+	suits := make(map[card.ID]bool)
+	winds, dragons, terminals, simples := false, false, false, false
+	green, reversible := true, true
+	minRank, maxRank := 9, 1
+	for i, n := range e.all {
+		if n == 0 {
+			continue
+		}
+		t := card.ID(i)
+		switch {
+		case isWind(t):
+			winds = true
+		case isDragon(t):
+			dragons = true
+		default:
+			suits[t/10] = true
+			if isTerminal(t) {
+				terminals = true
+			} else {
+				simples = true
+			}
+			if t.Rank() < minRank {
+				minRank = t.Rank()
+			}
+			if t.Rank() > maxRank {
+				maxRank = t.Rank()
+			}
+		}
+		green = green && greenTiles[t]
+		reversible = reversible && reversibleTiles[t]
+		if n == 4 && !e.kongs[t] {
+			fans = append(fans, TileHog)
+		}
+	}
+	honors := winds || dragons
+
+	switch {
+	case green:
+		fans = append(fans, AllGreen)
+	case reversible:
+		fans = append(fans, ReversibleTiles)
+	}
+	switch {
+	case len(suits) == 0:
+		fans = append(fans, AllHonors)
+	case !simples && !honors:
+		fans = append(fans, AllTerminals)
+	case !simples:
+		fans = append(fans, AllTerminalsAndHonors)
+	}
+	switch {
+	case len(suits) == 1 && !honors:
+		fans = append(fans, FullFlush)
+		if e.isNineGates() {
+			fans = append(fans, NineGates)
+		}
+	case len(suits) == 1:
+		fans = append(fans, HalfFlush)
+	case len(suits) == 2:
+		fans = append(fans, OneVoidedSuit)
+	}
+	if len(suits) == 3 && winds && dragons {
+		fans = append(fans, AllTypes)
+	}
+	if !honors {
+		fans = append(fans, NoHonors)
+		switch {
+		case minRank >= 7:
+			fans = append(fans, UpperTiles)
+		case minRank >= 4 && maxRank <= 6:
+			fans = append(fans, MiddleTiles)
+		case maxRank <= 3:
+			fans = append(fans, LowerTiles)
+		case minRank >= 6:
+			fans = append(fans, UpperFour)
+		case maxRank <= 4:
+			fans = append(fans, LowerFour)
+		}
+		if !terminals {
+			fans = append(fans, AllSimples)
+		}
+	}
+	return fans
+}
+
+// isNineGates 九莲宝灯：门清，去掉和的牌是同一门花色的 1112345678999
+func (e *evaluator) isNineGates() bool {
+	if len(e.Melds) != 0 {
+		return false
+	}
+	c := e.hand
+	c[e.WinTile]--
+	suit := e.WinTile / 10 * 10
+	for r := 1; r <= 9; r++ {
+		want := 1
+		if r == 1 || r == 9 {
+			want = 3
+		}
+		if c[suit+card.ID(r)] != want {
+			return false
+		}
+	}
+	return true
+}
+
+// situationFans 和牌方式计的番种
+func (e *evaluator) situationFans() []Fan {
+	var fans []Fan
+	if e.opened == 0 {
+		if e.SelfDrawn {
+			fans = append(fans, FullyConcealedHand)
+		} else {
+			fans = append(fans, ConcealedHand)
+		}
+	}
+	if e.SelfDrawn {
+		fans = append(fans, SelfDrawn)
+	}
+	if e.LastDraw {
+		if e.SelfDrawn {
+			fans = append(fans, LastTileDraw)
+		} else {
+			fans = append(fans, LastTileClaim)
+		}
+	}
+	if e.KongDraw && e.SelfDrawn {
+		fans = append(fans, OutWithReplacementTile)
+	}
+	if e.RobKong {
+		fans = append(fans, RobbingTheKong)
+	}
+	if e.LastTile {
+		fans = append(fans, LastTile)
+	}
+	for i := 0; i < e.Flowers; i++ {
+		fans = append(fans, FlowerTiles)
+	}
+	return fans
+}
+
+// greenTiles 绿一色的牌：23468 条和发
+var greenTiles = map[card.ID]bool{
+	card.MAHJONG_BAM2: true, card.MAHJONG_BAM3: true, card.MAHJONG_BAM4: true,
+	card.MAHJONG_BAM6: true, card.MAHJONG_BAM8: true, card.MAHJONG_GREE: true,
+}
+
+// reversibleTiles 推不倒的牌：1234589 饼、245689 条和白板
+var reversibleTiles = map[card.ID]bool{
+	card.MAHJONG_DOT1: true, card.MAHJONG_DOT2: true, card.MAHJONG_DOT3: true, card.MAHJONG_DOT4: true,
+	card.MAHJONG_DOT5: true, card.MAHJONG_DOT8: true, card.MAHJONG_DOT9: true,
+	card.MAHJONG_BAM2: true, card.MAHJONG_BAM4: true, card.MAHJONG_BAM5: true,
+	card.MAHJONG_BAM6: true, card.MAHJONG_BAM8: true, card.MAHJONG_BAM9: true,
+	card.MAHJONG_WHITE: true,
+}
+
+func isWind(t card.ID) bool {
+	return t >= card.MAHJONG_EAST && t <= card.MAHJONG_WEST
+}
+
+func isDragon(t card.ID) bool {
+	return t >= card.MAHJONG_GREE && t <= card.MAHJONG_WHITE
+}
+
+// isTerminal 数牌的 1 和 9
+func isTerminal(t card.ID) bool {
+	return t.IsSuit() && (t.Rank() == 1 || t.Rank() == 9)
+}
+
+func sameSuit(a, b card.ID) bool {
+	return a.IsSuit() && b.IsSuit() && a/10 == b/10
+}
+
+func sortedIDs(tiles []card.ID) []card.ID {
+	sorted := make([]card.ID, len(tiles))
+	copy(sorted, tiles)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
@@ -0,0 +1,241 @@
+// Package mcr 国标麻将（中国麻将竞赛规则）算番
+package mcr
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/game"
+	"github.com/mikodream/mahjong/win"
+)
+
+// ErrNotWin 不是和牌牌型
+var ErrNotWin = errors.New("mcr: not a winning hand")
+
+// MinPoints 起和番数，花牌不算在内
+const MinPoints = 8
+
+// Hand 和牌时的信息
+type Hand struct {
+	Tiles         []card.ID        // 手牌，包含和的那张牌，不包含明牌
+	Melds         []*game.ShowCard // 吃、碰、杠的牌，包括暗杠
+	WinTile       card.ID          // 和的那张牌
+	SelfDrawn     bool             // 自摸
+	SeatWind      card.ID          // 门风
+	PrevalentWind card.ID          // 圈风
+	Flowers       int              // 花牌的张数
+	LastTile      bool             // 和绝张：和的牌场上已经亮出了三张
+	LastDraw      bool             // 和的是牌墙的最后一张牌，或者最后一张牌打出的牌
+	KongDraw      bool             // 杠后补牌自摸
+	RobKong       bool             // 抢杠和
+}
+
+// Result 算番结果
+type Result struct {
+	Fans   []Fan // 计入的番种，按番数从高到低，同一番种可以计多次
+	Points int   // 总番数
+}
+
+// Qualified 是否够起和番，花牌不算在内
+func (r Result) Qualified() bool {
+	points := r.Points
+	for _, f := range r.Fans {
+		if f == FlowerTiles {
+			points -= f.Points()
+		}
+	}
+	return points >= MinPoints
+}
+
+// Score 算番：枚举所有拆法和和的那张牌所在的位置，取番数最高的
+func Score(h Hand) (Result, error) {
+	e, ok := newEvaluator(h)
+	if !ok {
+		return Result{}, ErrNotWin
+	}
+	best, found := Result{}, false
+	for _, c := range e.candidates() {
+		if r := newResult(e.fans(c)); !found || r.Points > best.Points {
+			best, found = r, true
+		}
+	}
+	if !found {
+		return Result{}, ErrNotWin
+	}
+	return best, nil
+}
+
+// newResult 按不计原则去掉被包含的番种，没有番种时计无番和
+func newResult(fans []Fan) Result {
+	sort.Slice(fans, func(i, j int) bool { return fans[i] < fans[j] })
+	excluded := make(map[Fan]bool)
+	r := Result{}
+	for _, f := range fans {
+		if excluded[f] {
+			continue
+		}
+		for _, x := range excludes[f] {
+			excluded[x] = true
+		}
+		r.Fans = append(r.Fans, f)
+		r.Points += f.Points()
+	}
+	if r.Points == 0 || (len(r.Fans) > 0 && r.Fans[0] == FlowerTiles) {
+		r.Fans = append([]Fan{ChickenHand}, r.Fans...)
+		r.Points += ChickenHand.Points()
+	}
+	return r
+}
+
+// candidate 一种拆法，以及和的那张牌在哪一组
+type candidate struct {
+	sets    []set // 包括明牌
+	special Fan   // 七对、十三幺、全不靠等特殊牌型，-1 表示一般牌型
+	winSet  int   // 和的那张牌所在的组的下标，-1 表示不区分
+}
+
+// evaluator 一手牌的算番过程中共用的数据
+type evaluator struct {
+	Hand
+	hand   tileCounts // 手牌
+	all    tileCounts // 手牌和明牌
+	melds  []win.Meld
+	waits  []card.ID // 听的牌，只听一张时才计边张、坎张、单钓将
+	kongs  map[card.ID]bool
+	opened int // 吃、碰、明杠的组数
+}
+
+func newEvaluator(h Hand) (*evaluator, bool) {
+	if !card.IDInSlice(h.WinTile, h.Tiles) || len(h.Tiles)+3*len(h.Melds) != 14 {
+		return nil, false
+	}
+	hand, ok := newTileCounts(h.Tiles)
+	if !ok {
+		return nil, false
+	}
+	e := &evaluator{Hand: h, hand: hand, all: hand, kongs: make(map[card.ID]bool)}
+	var meldTiles []card.ID
+	for _, m := range h.Melds {
+		tiles := m.GetTiles()
+		meldTiles = append(meldTiles, tiles...)
+		meld := win.NewMeld(m)
+		if meld.Kind == win.Kong {
+			e.kongs[meld.Tile] = true
+		}
+		if !meld.Concealed {
+			e.opened++
+		}
+		e.melds = append(e.melds, meld)
+		for _, t := range tiles {
+			if t <= 0 || int(t) >= len(e.all) {
+				return nil, false
+			}
+			e.all[t]++
+		}
+	}
+	rest := make([]card.ID, 0, len(h.Tiles)-1)
+	removed := false
+	for _, t := range h.Tiles {
+		if t == h.WinTile && !removed {
+			removed = true
+			continue
+		}
+		rest = append(rest, t)
+	}
+	e.waits = win.GetTingTiles(rest, meldTiles)
+	return e, true
+}
+
+// candidates 所有的拆法
+func (e *evaluator) candidates() []candidate {
+	var ret []candidate
+	if len(e.Melds) == 0 {
+		ret = append(ret, e.specialCandidates()...)
+	}
+	for _, d := range win.Decompose(e.Tiles, e.melds, e.WinTile, win.Options{}) {
+		sets := newSets(d.Melds)
+		if !e.SelfDrawn && sets[d.WinMeld].kind == pungSet {
+			// 点和的牌组成的刻子不算暗刻
+			sets[d.WinMeld].concealed = false
+		}
+		ret = append(ret, candidate{sets: sets, special: -1, winSet: d.WinMeld})
+	}
+	for _, sets := range decomposeKnitted(e.Tiles, e.melds) {
+		seen := make(map[set]bool)
+		// 明牌在最后，和的那张牌只能在手里的组
+		for i, s := range sets[:len(sets)-len(e.melds)] {
+			if !s.contains(e.WinTile) || seen[s] {
+				continue
+			}
+			seen[s] = true
+			all := append([]set{}, sets...)
+			if !e.SelfDrawn && s.kind == pungSet {
+				all[i].concealed = false
+			}
+			ret = append(ret, candidate{sets: all, special: -1, winSet: i})
+		}
+	}
+	return ret
+}
+
+// specialCandidates 十三幺、七对、全不靠
+func (e *evaluator) specialCandidates() []candidate {
+	var ret []candidate
+	sorted := make([]card.ID, len(e.Tiles))
+	copy(sorted, e.Tiles)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	if win.IsThirteenOrphans(sorted) {
+		ret = append(ret, candidate{special: ThirteenOrphans, winSet: -1})
+	}
+	pairs, singles := 0, 0
+	for _, n := range e.hand {
+		pairs += n / 2
+		singles += n % 2
+	}
+	if pairs == 7 {
+		special := SevenPairs
+		if isSevenShiftedPairs(e.hand) {
+			special = SevenShiftedPairs
+		}
+		ret = append(ret, candidate{special: special, winSet: -1})
+	}
+	if singles == 14 {
+		if i, ok := knittedPattern(sorted); ok {
+			special := LesserHonorsAndKnittedTiles
+			honors := 0
+			for _, t := range sorted {
+				if t.IsHonor() {
+					honors++
+				}
+			}
+			if honors == 7 {
+				special = GreaterHonorsAndKnittedTiles
+			}
+			c := candidate{special: special, winSet: -1}
+			if len(sorted)-honors == 9 {
+				c.sets = []set{{kind: knittedSet, tile: card.ID(i), concealed: true}}
+			}
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// isSevenShiftedPairs 连七对：同一门花色连续的七个对子
+func isSevenShiftedPairs(c tileCounts) bool {
+	first := -1
+	for t, n := range c {
+		if n == 0 {
+			continue
+		}
+		if first < 0 {
+			first = t
+		}
+		if n != 2 || t-first >= 7 || !card.ID(t).IsSuit() {
+			return false
+		}
+	}
+	return first >= 0 && card.ID(first).Rank() <= 3
+}
@@ -0,0 +1,199 @@
+package mcr
+
+import (
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+	"github.com/mikodream/mahjong/game"
+)
+
+func hasFan(r Result, f Fan) bool {
+	for _, x := range r.Fans {
+		if x == f {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFanTable(t *testing.T) {
+	if fanCount != 81 {
+		t.Errorf("国标麻将应该有 81 个番种, 实际 %d", fanCount)
+	}
+	for f := Fan(0); f < fanCount; f++ {
+		if f.String() == "" {
+			t.Errorf("番种 %d 没有名字", f)
+		}
+	}
+	if BigFourWinds.Points() != 88 || ChickenHand.Points() != 8 || FlowerTiles.Points() != 1 {
+		t.Error("番数错误")
+	}
+}
+
+func TestScore(t *testing.T) {
+	chi := func(tiles ...card.ID) *game.ShowCard {
+		return game.NewShowCard(consts.CHI, 1, tiles, true, false)
+	}
+	peng := func(tile card.ID) *game.ShowCard {
+		return game.NewShowCard(consts.PENG, 1, []card.ID{tile, tile, tile}, true, false)
+	}
+	cases := []struct {
+		name    string
+		hand    Hand
+		points  int
+		include []Fan
+		exclude []Fan
+	}{
+		{
+			name: "清龙 平和 门前清 单钓将",
+			hand: Hand{
+				Tiles:   []card.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 12, 13, 14, 25, 25},
+				WinTile: 25,
+			},
+			points:  21,
+			include: []Fan{PureStraight, AllChows, ConcealedHand, SingleWait},
+			exclude: []Fan{ShortStraight, TwoTerminalChows, NoHonors},
+		},
+		{
+			name: "大四喜 四暗刻 混幺九 混一色",
+			hand: Hand{
+				Tiles:         []card.ID{31, 31, 31, 32, 32, 32, 33, 33, 33, 34, 34, 34, 1, 1},
+				WinTile:       1,
+				SelfDrawn:     true,
+				SeatWind:      card.MAHJONG_EAST,
+				PrevalentWind: card.MAHJONG_EAST,
+			},
+			points:  88 + 64 + 32 + 6 + 1 + 1,
+			include: []Fan{BigFourWinds, FourConcealedPungs, AllTerminalsAndHonors, HalfFlush, SelfDrawn, SingleWait},
+			exclude: []Fan{AllPungs, PrevalentWind, SeatWind, BigThreeWinds, PungOfTerminalsOrHonors, FullyConcealedHand},
+		},
+		{
+			name: "七对自摸",
+			hand: Hand{
+				Tiles:     []card.ID{1, 1, 3, 3, 15, 15, 17, 17, 29, 29, 31, 31, 34, 34},
+				WinTile:   34,
+				SelfDrawn: true,
+			},
+			points:  25,
+			include: []Fan{SevenPairs, SelfDrawn},
+			exclude: []Fan{SingleWait, FullyConcealedHand},
+		},
+		{
+			name: "十三幺",
+			hand: Hand{
+				Tiles:   []card.ID{1, 9, 11, 19, 21, 29, 31, 32, 33, 34, 41, 42, 43, 43},
+				WinTile: 43,
+			},
+			points:  88,
+			include: []Fan{ThirteenOrphans},
+			exclude: []Fan{AllTypes, AllTerminalsAndHonors, ConcealedHand},
+		},
+		{
+			name: "九莲宝灯",
+			hand: Hand{
+				Tiles:   []card.ID{1, 1, 1, 2, 3, 4, 5, 5, 6, 7, 8, 9, 9, 9},
+				WinTile: 5,
+			},
+			include: []Fan{NineGates},
+			exclude: []Fan{FullFlush, ConcealedHand, PungOfTerminalsOrHonors},
+		},
+		{
+			name: "无番和",
+			hand: Hand{
+				Tiles:         []card.ID{6, 7, 8, 33, 33},
+				Melds:         []*game.ShowCard{chi(1, 2, 3), chi(15, 16, 17), chi(22, 23, 24)},
+				WinTile:       8,
+				SeatWind:      card.MAHJONG_EAST,
+				PrevalentWind: card.MAHJONG_EAST,
+			},
+			points:  8,
+			include: []Fan{ChickenHand},
+		},
+		{
+			name: "全求人 碰碰和",
+			hand: Hand{
+				Tiles:   []card.ID{5, 5},
+				Melds:   []*game.ShowCard{peng(2), peng(14), peng(26), peng(28)},
+				WinTile: 5,
+			},
+			include: []Fan{MeldedHand, AllPungs, AllSimples},
+			exclude: []Fan{SingleWait},
+		},
+		{
+			name: "组合龙 五门齐",
+			hand: Hand{
+				Tiles:         []card.ID{1, 4, 7, 12, 15, 18, 23, 26, 29, 31, 31, 31, 41, 41},
+				WinTile:       29,
+				SeatWind:      card.MAHJONG_SOUTH,
+				PrevalentWind: card.MAHJONG_SOUTH,
+			},
+			points:  12 + 6 + 2 + 1,
+			include: []Fan{KnittedStraight, AllTypes, ConcealedHand, PungOfTerminalsOrHonors},
+		},
+		{
+			name: "全不靠",
+			hand: Hand{
+				Tiles:   []card.ID{1, 4, 7, 12, 15, 18, 23, 26, 31, 32, 33, 34, 41, 42},
+				WinTile: 42,
+			},
+			points:  12,
+			include: []Fan{LesserHonorsAndKnittedTiles},
+			exclude: []Fan{AllTypes, ConcealedHand},
+		},
+		{
+			name: "一色三步高 套算一次",
+			hand: Hand{
+				Tiles:   []card.ID{1, 2, 3, 2, 3, 4, 3, 4, 5, 12, 13, 14, 29, 29},
+				WinTile: 14,
+			},
+			include: []Fan{PureShiftedChows, MixedDoubleChow},
+			exclude: []Fan{PureDoubleChow, ShortStraight},
+		},
+	}
+	for _, c := range cases {
+		r, err := Score(c.hand)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if c.points > 0 && r.Points != c.points {
+			t.Errorf("%s: 期望 %d 番, 实际 %d 番 %v", c.name, c.points, r.Points, r.Fans)
+		}
+		for _, f := range c.include {
+			if !hasFan(r, f) {
+				t.Errorf("%s: 应该有 %s, 实际 %v", c.name, f, r.Fans)
+			}
+		}
+		for _, f := range c.exclude {
+			if hasFan(r, f) {
+				t.Errorf("%s: 不应该有 %s, 实际 %v", c.name, f, r.Fans)
+			}
+		}
+	}
+}
+
+func TestQualified(t *testing.T) {
+	// 只有平和，不够 8 番
+	r, err := Score(Hand{
+		Tiles: []card.ID{6, 7, 8, 25, 25},
+		Melds: []*game.ShowCard{
+			game.NewShowCard(consts.CHI, 1, []card.ID{1, 2, 3}, true, false),
+			game.NewShowCard(consts.CHI, 1, []card.ID{15, 16, 17}, true, false),
+			game.NewShowCard(consts.CHI, 1, []card.ID{22, 23, 24}, true, false),
+		},
+		WinTile: 8,
+		Flowers: 8,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Qualified() || r.Points != 2+8 {
+		t.Errorf("花牌不算起和番: %d %v", r.Points, r.Fans)
+	}
+
+	notWin := Hand{Tiles: []card.ID{1, 2, 4, 5, 5, 7, 9, 11, 13, 15, 17, 19, 21, 23}, WinTile: 23}
+	if _, err := Score(notWin); err != ErrNotWin {
+		t.Errorf("没有和牌应该返回 ErrNotWin, 实际 %v", err)
+	}
+}
@@ -0,0 +1,654 @@
+package riichi
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/game"
+	"github.com/mikodream/mahjong/win"
+)
+
+var (
+	// ErrNotWin 不是和牌牌型
+	ErrNotWin = errors.New("riichi: not a winning hand")
+	// ErrNoYaku 和牌牌型但是没有役，宝牌不算役
+	ErrNoYaku = errors.New("riichi: no yaku")
+)
+
+// Hand 和牌时的信息
+type Hand struct {
+	Tiles        []card.ID        // 手牌，包含和的那张牌，不包含明牌
+	Melds        []*game.ShowCard // 吃、碰、杠的牌，包括暗杠
+	WinTile      card.ID          // 和的那张牌
+	SelfDrawn    bool             // 自摸
+	Dealer       bool             // 庄家
+	SeatWind     card.ID          // 自风
+	RoundWind    card.ID          // 场风
+	Riichi       bool             // 立直
+	DoubleRiichi bool             // 两立直，和 Riichi 只设一个
+	Ippatsu      bool             // 立直后一巡内没有人鸣牌就和了
+	LastDraw     bool             // 和的是牌墙的最后一张牌，或者最后一张牌打出的牌
+	KongDraw     bool             // 杠后补牌自摸
+	RobKong      bool             // 抢杠
+	FirstDraw    bool             // 第一巡没有人鸣牌时自摸，庄家是天和，闲家是地和
+	Dora         []card.ID        // 宝牌（不是指示牌），同一张牌可以出现多次
+	UraDora      []card.ID        // 里宝牌，立直时才算
+	RedFives     int              // 赤宝牌的张数
+}
+
+// Result 算番结果
+type Result struct {
+	Yaku    []Yaku // 役种，役满时只有役满的役种
+	Han     int    // 番数，包括宝牌
+	Fu      int    // 符数，已经进位到 10
+	Dora    int    // 宝牌、里宝牌、赤宝牌的总数，已经计入 Han
+	Yakuman int    // 役满的倍数，大于 0 时不计 Han 和 Fu
+}
+
+// Evaluate 算番：枚举所有拆法和和的那张牌所在的位置，取点数最高的
+func Evaluate(h Hand) (Result, error) {
+	e, ok := newEvaluator(h)
+	if !ok {
+		return Result{}, ErrNotWin
+	}
+	candidates := e.candidates()
+	if len(candidates) == 0 {
+		return Result{}, ErrNotWin
+	}
+	best, found := Result{}, false
+	for _, c := range candidates {
+		yaku, fu := e.yaku(c)
+		if len(yaku) == 0 {
+			continue
+		}
+		r := e.newResult(yaku, fu)
+		if !found || better(r, best) {
+			best, found = r, true
+		}
+	}
+	if !found {
+		return Result{}, ErrNoYaku
+	}
+	return best, nil
+}
+
+// better 基本点高的好，一样时番数高的好，再一样时符数高的好
+func better(a, b Result) bool {
+	if a.BasePoints() != b.BasePoints() {
+		return a.BasePoints() > b.BasePoints()
+	}
+	if a.Han != b.Han {
+		return a.Han > b.Han
+	}
+	return a.Fu > b.Fu
+}
+
+// newResult 有役满时只计役满，否则加上宝牌
+func (e *evaluator) newResult(yaku []Yaku, fu int) Result {
+	var yakuman []Yaku
+	for _, y := range yaku {
+		if y.IsYakuman() {
+			yakuman = append(yakuman, y)
+		}
+	}
+	if len(yakuman) > 0 {
+		return Result{Yaku: yakuman, Yakuman: len(yakuman)}
+	}
+	r := Result{Yaku: yaku, Fu: fu, Dora: e.dora()}
+	for _, y := range yaku {
+		r.Han += y.Han(e.opened)
+	}
+	r.Han += r.Dora
+	return r
+}
+
+// dora 宝牌的张数，杠算四张
+func (e *evaluator) dora() int {
+	n := e.RedFives
+	for _, d := range e.Dora {
+		n += e.all[d]
+	}
+	if e.Riichi || e.DoubleRiichi {
+		for _, d := range e.UraDora {
+			n += e.all[d]
+		}
+	}
+	return n
+}
+
+// setKind 拆牌后每一组牌的类型
+type setKind int
+
+const (
+	chowSet setKind = iota // 顺子
+	pungSet                // 刻子
+	kongSet                // 杠
+	pairSet                // 雀头
+)
+
+// set 拆牌后的一组牌
+type set struct {
+	kind      setKind
+	tile      card.ID // 顺子是最小的一张
+	concealed bool    // 是否暗刻、暗杠，点和的牌组成的刻子不算
+}
+
+// tiles 这组牌包含的牌
+func (s set) tiles() []card.ID {
+	switch s.kind {
+	case chowSet:
+		return []card.ID{s.tile, s.tile + 1, s.tile + 2}
+	case pungSet:
+		return []card.ID{s.tile, s.tile, s.tile}
+	case kongSet:
+		return []card.ID{s.tile, s.tile, s.tile, s.tile}
+	}
+	return []card.ID{s.tile, s.tile}
+}
+
+// isPung 刻子或者杠
+func (s set) isPung() bool {
+	return s.kind == pungSet || s.kind == kongSet
+}
+
+// tileCounts 按 card.ID 下标的张数
+type tileCounts [card.MAHJONG_WHITE + 1]int
+
+// newTileCounts 统计牌的张数，有花牌或者不存在的牌时返回 false
+func newTileCounts(tiles []card.ID) (tileCounts, bool) {
+	var c tileCounts
+	for _, t := range tiles {
+		if t <= 0 || int(t) >= len(c) || t.Rank() == 0 {
+			return c, false
+		}
+		c[t]++
+	}
+	return c, true
+}
+
+// candidate 一种拆法，以及和的那张牌在哪一组
+type candidate struct {
+	sets    []set // 包括明牌，第一组是雀头
+	special Yaku  // 国士无双、七对子，-1 表示一般牌型
+	winSet  int   // 和的那张牌所在的组的下标
+}
+
+// evaluator 一手牌的算番过程中共用的数据
+type evaluator struct {
+	Hand
+	hand   tileCounts // 手牌
+	all    tileCounts // 手牌和明牌
+	melds  []win.Meld
+	opened bool // 有吃、碰、明杠
+}
+
+func newEvaluator(h Hand) (*evaluator, bool) {
+	if !card.IDInSlice(h.WinTile, h.Tiles) || len(h.Tiles)+3*len(h.Melds) != 14 {
+		return nil, false
+	}
+	hand, ok := newTileCounts(h.Tiles)
+	if !ok {
+		return nil, false
+	}
+	e := &evaluator{Hand: h, hand: hand, all: hand}
+	for _, m := range h.Melds {
+		meld := win.NewMeld(m)
+		e.opened = e.opened || !meld.Concealed
+		e.melds = append(e.melds, meld)
+		for _, t := range m.GetTiles() {
+			if t <= 0 || int(t) >= len(e.all) {
+				return nil, false
+			}
+			e.all[t]++
+		}
+	}
+	return e, true
+}
+
+// candidates 所有的拆法
+func (e *evaluator) candidates() []candidate {
+	var ret []candidate
+	if len(e.Melds) == 0 {
+		sorted := make([]card.ID, len(e.Tiles))
+		copy(sorted, e.Tiles)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		if win.IsThirteenOrphans(sorted) {
+			return []candidate{{special: KokushiMusou}}
+		}
+		pairs := 0
+		for _, n := range e.hand {
+			if n == 2 {
+				pairs++
+			}
+		}
+		if pairs == 7 {
+			ret = append(ret, candidate{special: Chiitoitsu})
+		}
+	}
+	for _, d := range win.Decompose(e.Tiles, e.melds, e.WinTile, win.Options{}) {
+		sets := make([]set, len(d.Melds))
+		for i, m := range d.Melds {
+			sets[i] = newSet(m)
+		}
+		if !e.SelfDrawn && sets[d.WinMeld].kind == pungSet {
+			// 点和的牌组成的刻子算明刻
+			sets[d.WinMeld].concealed = false
+		}
+		ret = append(ret, candidate{sets: sets, special: -1, winSet: d.WinMeld})
+	}
+	return ret
+}
+
+// newSet 把 win.Decompose 拆出来的一组牌转换成算番用的 set
+func newSet(m win.Meld) set {
+	s := set{kind: pairSet, tile: m.Tile, concealed: m.Concealed}
+	switch m.Kind {
+	case win.Chow:
+		s.kind = chowSet
+	case win.Pung:
+		s.kind = pungSet
+	case win.Kong:
+		s.kind = kongSet
+	}
+	return s
+}
+
+// waitKind 听牌的形状
+type waitKind int
+
+const (
+	ryanmen waitKind = iota // 两面
+	shanpon                 // 双碰
+	kanchan                 // 坎张
+	penchan                 // 边张
+	tanki                   // 单骑
+)
+
+// wait 和的那张牌在这组牌里时的听牌形状
+func wait(s set, t card.ID) waitKind {
+	switch {
+	case s.kind == pairSet:
+		return tanki
+	case s.kind != chowSet:
+		return shanpon
+	case t == s.tile+1:
+		return kanchan
+	case t == s.tile && s.tile.Rank() == 7, t == s.tile+2 && s.tile.Rank() == 1:
+		return penchan
+	}
+	return ryanmen
+}
+
+// yaku 一种拆法的役种和符数，役种包括宝牌以外的所有役
+func (e *evaluator) yaku(c candidate) ([]Yaku, int) {
+	yaku := e.situationYaku()
+	switch c.special {
+	case KokushiMusou:
+		return append(yaku, KokushiMusou), 0
+	case Chiitoitsu:
+		return append(yaku, e.chiitoitsuYaku()...), 25
+	}
+
+	var chows, pungs []card.ID
+	concealedPungs, kongs := 0, 0
+	for _, s := range c.sets {
+		switch {
+		case s.kind == chowSet:
+			chows = append(chows, s.tile)
+		case s.isPung():
+			pungs = append(pungs, s.tile)
+			if s.concealed {
+				concealedPungs++
+			}
+			if s.kind == kongSet {
+				kongs++
+			}
+		}
+	}
+	pair := c.sets[0].tile
+	w := wait(c.sets[c.winSet], e.WinTile)
+
+	pinfu := !e.opened && len(chows) == 4 && !e.isValuePair(pair) && w == ryanmen
+	if pinfu {
+		yaku = append(yaku, Pinfu)
+	}
+	if !e.opened {
+		switch peikou(chows) {
+		case 1:
+			yaku = append(yaku, Iipeikou)
+		case 2:
+			yaku = append(yaku, Ryanpeikou)
+		}
+	}
+	for _, p := range pungs {
+		switch p {
+		case card.MAHJONG_WHITE:
+			yaku = append(yaku, Haku)
+		case card.MAHJONG_GREE:
+			yaku = append(yaku, Hatsu)
+		case card.MAHJONG_RED:
+			yaku = append(yaku, Chun)
+		}
+		if p == e.SeatWind {
+			yaku = append(yaku, SeatWind)
+		}
+		if p == e.RoundWind {
+			yaku = append(yaku, RoundWind)
+		}
+	}
+	if len(chows) > 0 && allSets(c.sets, func(s set) bool { return hasTerminalOrHonor(s.tiles()) }) {
+		if e.hasHonors() {
+			yaku = append(yaku, Chanta)
+		} else {
+			yaku = append(yaku, Junchan)
+		}
+	}
+	if hasIttsu(chows) {
+		yaku = append(yaku, Ittsu)
+	}
+	if hasThreeSuits(chows) {
+		yaku = append(yaku, SanshokuDoujun)
+	}
+	if hasThreeSuits(pungs) {
+		yaku = append(yaku, SanshokuDoukou)
+	}
+	switch concealedPungs {
+	case 3:
+		yaku = append(yaku, Sanankou)
+	case 4:
+		yaku = append(yaku, Suuankou)
+	}
+	switch kongs {
+	case 3:
+		yaku = append(yaku, Sankantsu)
+	case 4:
+		yaku = append(yaku, Suukantsu)
+	}
+	if len(pungs) == 4 {
+		yaku = append(yaku, Toitoi)
+	}
+
+	dragons, winds := 0, 0
+	for _, p := range pungs {
+		if isDragon(p) {
+			dragons++
+		}
+		if isWind(p) {
+			winds++
+		}
+	}
+	switch {
+	case dragons == 3:
+		yaku = append(yaku, Daisangen)
+	case dragons == 2 && isDragon(pair):
+		yaku = append(yaku, Shousangen)
+	}
+	switch {
+	case winds == 4:
+		yaku = append(yaku, Daisuushii)
+	case winds == 3 && isWind(pair):
+		yaku = append(yaku, Shousuushii)
+	}
+	if !e.opened && len(e.Melds) == 0 && e.isNineGates() {
+		yaku = append(yaku, ChuurenPoutou)
+	}
+	yaku = append(yaku, e.tileYaku()...)
+	if len(yaku) == 0 {
+		return nil, 0
+	}
+	return yaku, e.fu(c, pinfu, w)
+}
+
+// situationYaku 和牌时的状况役，和拆法无关
+func (e *evaluator) situationYaku() []Yaku {
+	var yaku []Yaku
+	switch {
+	case e.DoubleRiichi:
+		yaku = append(yaku, DoubleRiichi)
+	case e.Riichi:
+		yaku = append(yaku, Riichi)
+	}
+	if e.Ippatsu && (e.Riichi || e.DoubleRiichi) {
+		yaku = append(yaku, Ippatsu)
+	}
+	if e.SelfDrawn && !e.opened {
+		yaku = append(yaku, MenzenTsumo)
+	}
+	switch {
+	case e.KongDraw && e.SelfDrawn:
+		yaku = append(yaku, Rinshan)
+	case e.LastDraw && e.SelfDrawn:
+		yaku = append(yaku, Haitei)
+	case e.LastDraw:
+		yaku = append(yaku, Houtei)
+	}
+	if e.RobKong && !e.SelfDrawn {
+		yaku = append(yaku, Chankan)
+	}
+	if e.FirstDraw && e.SelfDrawn && len(e.Melds) == 0 {
+		if e.Dealer {
+			yaku = append(yaku, Tenhou)
+		} else {
+			yaku = append(yaku, Chiihou)
+		}
+	}
+	return yaku
+}
+
+// chiitoitsuYaku 七对子可以复合的役
+func (e *evaluator) chiitoitsuYaku() []Yaku {
+	return append([]Yaku{Chiitoitsu}, e.tileYaku()...)
+}
+
+// tileYaku 只看用了哪些牌的役：断幺九、混老头、混一色、清一色、字一色、绿一色、清老头
+func (e *evaluator) tileYaku() []Yaku {
+	simples, terminals, honors, green := true, true, true, true
+	suits := make(map[card.ID]bool)
+	for t, n := range e.all {
+		if n == 0 {
+			continue
+		}
+		id := card.ID(t)
+		if id.IsSuit() {
+			suits[id/10] = true
+			honors = false
+			if isTerminal(id) {
+				simples = false
+			} else {
+				terminals = false
+			}
+		} else {
+			simples, terminals = false, false
+		}
+		green = green && greenTiles[id]
+	}
+	var yaku []Yaku
+	switch {
+	case honors:
+		yaku = append(yaku, Tsuuiisou)
+	case terminals:
+		yaku = append(yaku, Chinroutou)
+	case simples:
+		yaku = append(yaku, Tanyao)
+	case allTerminalOrHonor(e.all):
+		yaku = append(yaku, Honroutou)
+	}
+	if green {
+		yaku = append(yaku, Ryuuiisou)
+	}
+	if len(suits) == 1 {
+		if e.hasHonors() {
+			yaku = append(yaku, Honitsu)
+		} else {
+			yaku = append(yaku, Chinitsu)
+		}
+	}
+	return yaku
+}
+
+// fu 符数：副底 20 符，门前清荣和 10 符，自摸 2 符，刻子、杠、役牌雀头、听牌形状另算
+// 平和自摸 20 符，副露时不满 30 符按 30 符算
+func (e *evaluator) fu(c candidate, pinfu bool, w waitKind) int {
+	if pinfu {
+		if e.SelfDrawn {
+			return 20
+		}
+		return 30
+	}
+	fu := 20
+	if !e.opened && !e.SelfDrawn {
+		fu += 10
+	}
+	if e.SelfDrawn {
+		fu += 2
+	}
+	for _, s := range c.sets {
+		if !s.isPung() {
+			continue
+		}
+		f := 2
+		if hasTerminalOrHonor(s.tiles()[:1]) {
+			f *= 2
+		}
+		if s.concealed {
+			f *= 2
+		}
+		if s.kind == kongSet {
+			f *= 4
+		}
+		fu += f
+	}
+	pair := c.sets[0].tile
+	if isDragon(pair) {
+		fu += 2
+	}
+	if pair == e.SeatWind {
+		fu += 2
+	}
+	if pair == e.RoundWind {
+		fu += 2
+	}
+	if w == kanchan || w == penchan || w == tanki {
+		fu += 2
+	}
+	fu = (fu + 9) / 10 * 10
+	if fu == 20 {
+		fu = 30
+	}
+	return fu
+}
+
+// isValuePair 役牌做雀头
+func (e *evaluator) isValuePair(t card.ID) bool {
+	return isDragon(t) || t == e.SeatWind || t == e.RoundWind
+}
+
+func (e *evaluator) hasHonors() bool {
+	for t := card.MAHJONG_EAST; t <= card.MAHJONG_WHITE; t++ {
+		if e.all[t] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isNineGates 九莲宝灯：同一门花色 1112345678999 加一张
+func (e *evaluator) isNineGates() bool {
+	suit := e.WinTile / 10 * 10
+	if !e.WinTile.IsSuit() {
+		return false
+	}
+	total := 0
+	for r := card.ID(1); r <= 9; r++ {
+		n := e.hand[suit+r]
+		need := 1
+		if r == 1 || r == 9 {
+			need = 3
+		}
+		if n < need {
+			return false
+		}
+		total += n
+	}
+	return total == 14
+}
+
+// peikou 一样的顺子有几对
+func peikou(chows []card.ID) int {
+	counts := make(map[card.ID]int)
+	pairs := 0
+	for _, c := range chows {
+		counts[c]++
+		if counts[c]%2 == 0 {
+			pairs++
+		}
+	}
+	return pairs
+}
+
+// hasIttsu 同一门花色的 123、456、789
+func hasIttsu(chows []card.ID) bool {
+	for _, suit := range []card.ID{0, 10, 20} {
+		if card.IDInSlice(suit+1, chows) && card.IDInSlice(suit+4, chows) && card.IDInSlice(suit+7, chows) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasThreeSuits 三门花色各有一组一样的顺子或者刻子
+func hasThreeSuits(tiles []card.ID) bool {
+	for _, t := range tiles {
+		if t.IsSuit() && t < 10 && card.IDInSlice(t+10, tiles) && card.IDInSlice(t+20, tiles) {
+			return true
+		}
+	}
+	return false
+}
+
+func allSets(sets []set, f func(s set) bool) bool {
+	for _, s := range sets {
+		if !f(s) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTerminalOrHonor(tiles []card.ID) bool {
+	for _, t := range tiles {
+		if t.IsHonor() || isTerminal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func allTerminalOrHonor(c tileCounts) bool {
+	for t, n := range c {
+		if n > 0 && !hasTerminalOrHonor([]card.ID{card.ID(t)}) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWind(t card.ID) bool {
+	return t >= card.MAHJONG_EAST && t <= card.MAHJONG_WEST
+}
+
+func isDragon(t card.ID) bool {
+	return t >= card.MAHJONG_GREE && t <= card.MAHJONG_WHITE
+}
+
+// isTerminal 数牌的 1 和 9
+func isTerminal(t card.ID) bool {
+	return t.IsSuit() && (t.Rank() == 1 || t.Rank() == 9)
+}
+
+// greenTiles 绿一色的牌：23468 条和发
+var greenTiles = map[card.ID]bool{
+	card.MAHJONG_BAM2: true, card.MAHJONG_BAM3: true, card.MAHJONG_BAM4: true,
+	card.MAHJONG_BAM6: true, card.MAHJONG_BAM8: true, card.MAHJONG_GREE: true,
+}
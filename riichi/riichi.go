@@ -0,0 +1,483 @@
+// Package riichi 日本麻将（立直麻将）
+package riichi
+
+import (
+	"context"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+	"github.com/mikodream/mahjong/game"
+	"github.com/mikodream/mahjong/ting"
+)
+
+const (
+	deadWallSize  = 14   // 王牌的张数
+	rinshanTiles  = 4    // 王牌里的岭上牌，后面依次是宝牌指示牌和里宝牌指示牌
+	maxIndicators = 5    // 宝牌指示牌最多翻开五张：一张加四次杠
+	riichiStick   = 1000 // 立直棒
+	honbaPoints   = 300  // 每一本场荣和加 300 点，自摸每家加 100 点
+	notenPenalty  = 1000 // 流局罚符按其他玩家的人数算，四人一共 3000 点，三人一共 2000 点
+)
+
+// RiichiDecider 玩家出牌之后，手牌门前清并且听牌时询问是否立直
+// tiles 是出牌之后的手牌，没有实现这个接口的玩家不会立直
+type RiichiDecider interface {
+	DeclareRiichi(tiles []card.ID, gameState game.State) (bool, error)
+}
+
+// Rules 日本麻将
+// 136 张牌，起手 13 张，牌墙最后 14 张是王牌，从中翻宝牌指示牌，杠后补牌不减少王牌
+// 和牌至少要有一役（宝牌不算役），荣和时不能振听：听的牌自己打过，或者见逃之后还没有再出牌
+// 立直后不能鸣牌，只能摸切，只有不改变听的牌的暗杠可以杠，见逃之后永久振听
+// 每局牌的立直、振听记录在 Rules 中，所以每个 Game 要用一个新的 Rules；
+// 本场数和供托由调用方在局与局之间维护，用 game.Match 打一场比赛时见 MatchRules
+// 三人麻将（三麻）去掉二万到八万，不能吃，一万的指示牌翻出来宝牌是九万，
+// 自摸时只有另外两家付钱（自摸损）
+// 使用赤宝牌时每门花色的 5 有一张是红的，赤 5 是牌墙里实实在在的一张牌，见 game.PlayerController.RedTiles
+type Rules struct {
+	game.BaseRules
+	RoundWind card.ID // 场风，默认东
+	Honba     int     // 本场数
+	Sticks    int     // 场上的立直棒（上一局流局留下的），和牌的玩家拿走，流局时加上这局的立直棒
+	RedFives  bool    // 每门花色各有一张赤 5
+
+	dealer     *game.PlayerController
+	winds      map[int]card.ID
+	riichi     map[int]*riichiState // 玩家 ID => 立直状态
+	furiten    map[int]bool         // 见逃之后还没有再出牌的玩家
+	kongs      map[int]int          // 玩家 ID => 上次出牌时杠的组数，用于判断岭上开花
+	melds      int                  // 上次出牌时所有玩家明牌的组数加杠的组数，用于判断一发、两立直
+	pending    *game.PlayerController
+	lastTile   card.ID
+	lastPlayer *game.PlayerController
+}
+
+// riichiState 一个玩家的立直状态
+type riichiState struct {
+	double  bool      // 两立直
+	ippatsu bool      // 一发还有效
+	furiten bool      // 立直后见逃
+	waits   []card.ID // 立直时听的牌
+}
+
+// New 创建日本麻将规则，东场，使用赤宝牌
+func New() *Rules {
+	return &Rules{RoundWind: card.MAHJONG_EAST, RedFives: true}
+}
+
+// NewSanma 创建三人麻将规则，东场，使用赤宝牌
+func NewSanma() *Rules {
+	r := New()
+	r.Players = 3
+	return r
+}
+
+// MatchRules 一场比赛每局用的规则：按 base 的设置创建，场风和本场数由 game.Match 决定，
+// 场上的立直棒从上一局延续下来
+func MatchRules(base *Rules) game.RulesFactory {
+	return func(hand game.HandInfo, prev game.RuleSet) game.RuleSet {
+		r := &Rules{
+			BaseRules: base.BaseRules,
+			RoundWind: hand.RoundWind,
+			Honba:     hand.Honba,
+			Sticks:    base.Sticks,
+			RedFives:  base.RedFives,
+		}
+		if p, ok := prev.(*Rules); ok {
+			r.Sticks = p.Sticks
+		}
+		return r
+	}
+}
+
+// KeepDealer 庄家和牌，或者流局时庄家听牌，庄家连庄
+func (r *Rules) KeepDealer(g *game.Game, result *game.HandResult) bool {
+	for _, w := range result.Wins {
+		if w.Winner == r.dealer {
+			return true
+		}
+	}
+	return result.Exhausted && len(r.waits(r.dealer)) > 0
+}
+
+// AfterDeal 确定庄家和门风，切出王牌
+func (r *Rules) AfterDeal(ctx context.Context, g *game.Game) error {
+	if r.RoundWind == 0 {
+		r.RoundWind = card.MAHJONG_EAST
+	}
+	r.dealer = g.Players().Peek()
+	r.winds = make(map[int]card.ID)
+	for i := 0; i < g.Players().Len() && i < len(card.Winds); i++ {
+		r.winds[g.Players().After(r.dealer.ID(), i).ID()] = card.Winds[i]
+	}
+	g.Deck().SetDeadWall(deadWallSize)
+	r.riichi = make(map[int]*riichiState)
+	r.furiten = make(map[int]bool)
+	r.kongs = make(map[int]int)
+	r.melds, r.pending, r.lastTile, r.lastPlayer = 0, nil, 0, nil
+	return nil
+}
+
+// RedTiles 使用赤宝牌时每门花色的 5 有一张是红的
+func (r *Rules) RedTiles() []card.ID {
+	if !r.RedFives {
+		return nil
+	}
+	return []card.ID{card.MAHJONG_CRAK5, card.MAHJONG_BAM5, card.MAHJONG_DOT5}
+}
+
+// Dealer 庄家
+func (r *Rules) Dealer() *game.PlayerController {
+	return r.dealer
+}
+
+// SeatWind 玩家的门风
+func (r *Rules) SeatWind(player *game.PlayerController) card.ID {
+	return r.winds[player.ID()]
+}
+
+// Indicators 已经翻开的宝牌指示牌，开局一张，每杠一次多翻一张
+func (r *Rules) Indicators(g *game.Game) []card.ID {
+	n := 1 + totalKongs(g)
+	if n > maxIndicators {
+		n = maxIndicators
+	}
+	return g.Deck().DeadWall()[rinshanTiles : rinshanTiles+n]
+}
+
+// Dora 宝牌：指示牌的下一张
+func (r *Rules) Dora(g *game.Game) []card.ID {
+	return r.dora(r.Indicators(g))
+}
+
+// UraDora 里宝牌：宝牌指示牌下面的牌的下一张，和牌时才翻开
+func (r *Rules) UraDora(g *game.Game) []card.ID {
+	n := len(r.Indicators(g))
+	ura := rinshanTiles + maxIndicators
+	return r.dora(g.Deck().DeadWall()[ura : ura+n])
+}
+
+// dora 宝牌，三人麻将没有二万到八万，一万的下一张是九万
+func (r *Rules) dora(indicators []card.ID) []card.ID {
+	dora := doraOf(indicators)
+	if r.Players != 3 {
+		return dora
+	}
+	for i, t := range dora {
+		if t == card.MAHJONG_CRAK2 {
+			dora[i] = card.MAHJONG_CRAK9
+		}
+	}
+	return dora
+}
+
+// InRiichi 玩家是否已经立直
+func (r *Rules) InRiichi(player *game.PlayerController) bool {
+	return r.riichi[player.ID()] != nil
+}
+
+// AllowWin 至少要有一役，荣和时不能振听
+func (r *Rules) AllowWin(g *game.Game, player *game.PlayerController, hand []card.ID) bool {
+	selfDrawn := len(player.Hand())%3 == 2
+	winTile := hand[len(hand)-1]
+	if !selfDrawn && r.Furiten(g, player) {
+		return false
+	}
+	// 四张都已经杠出来了，荣和的只能是补杠的那张
+	robKong := !selfDrawn && isKonged(g, winTile)
+	_, err := Evaluate(r.newHand(g, player, hand, winTile, selfDrawn, robKong))
+	return err == nil
+}
+
+// Furiten 振听：听的牌自己打过，或者见逃之后还没有再出牌，立直后见逃一直振听
+func (r *Rules) Furiten(g *game.Game, player *game.PlayerController) bool {
+	if r.furiten[player.ID()] {
+		return true
+	}
+	if st := r.riichi[player.ID()]; st != nil && st.furiten {
+		return true
+	}
+	discards := g.Pile().Discards(player)
+	for _, t := range r.waits(player) {
+		if card.IDInSlice(t, discards) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowPlayerMeld 立直后不能吃碰杠，只能用摸到的牌暗杠，并且杠了之后听的牌不变
+func (r *Rules) AllowPlayerMeld(g *game.Game, player *game.PlayerController, op int, tile card.ID) bool {
+	st := r.riichi[player.ID()]
+	if st == nil {
+		return true
+	}
+	if op != consts.AN_GANG || tile != player.LastTile() {
+		return false
+	}
+	hand := make([]card.ID, 0, len(player.Hand()))
+	for _, t := range player.Hand() {
+		if t != tile {
+			hand = append(hand, t)
+		}
+	}
+	show := append(player.GetShowCardTiles(), tile, tile, tile, tile)
+	_, waits := ting.CanTingWith(hand, show, r.WinOptions())
+	return sameTiles(waits, st.waits)
+}
+
+// ForcedDiscard 立直后摸什么打什么
+func (r *Rules) ForcedDiscard(g *game.Game, player *game.PlayerController) (card.ID, bool) {
+	if r.riichi[player.ID()] == nil {
+		return 0, false
+	}
+	return player.LastTile(), true
+}
+
+// AfterDiscard 记录见逃和一发，询问立直
+func (r *Rules) AfterDiscard(g *game.Game, player *game.PlayerController, tile card.ID) error {
+	r.passed(g)
+	r.lastTile, r.lastPlayer, r.pending = tile, player, nil
+	if melds := meldsAndKongs(g); melds != r.melds {
+		r.melds = melds
+		for _, st := range r.riichi {
+			st.ippatsu = false
+		}
+	}
+	delete(r.furiten, player.ID())
+	r.kongs[player.ID()] = totalKongs(g, player)
+
+	if st := r.riichi[player.ID()]; st != nil {
+		st.ippatsu = false
+		return nil
+	}
+	decider, ok := player.Player().(RiichiDecider)
+	if !ok || isOpened(player) || g.Deck().Len() < g.Players().Len() {
+		return nil
+	}
+	waits := r.waits(player)
+	if len(waits) == 0 {
+		return nil
+	}
+	declare, err := decider.DeclareRiichi(player.Hand(), g.ExtractState(player))
+	if err != nil || !declare {
+		return err
+	}
+	r.riichi[player.ID()] = &riichiState{
+		double:  len(g.Pile().Discards(player)) == 1 && r.melds == 0,
+		ippatsu: true,
+		waits:   waits,
+	}
+	r.pending = player
+	return nil
+}
+
+// passed 上一张打出的牌没有人荣和，听这张牌的玩家见逃
+func (r *Rules) passed(g *game.Game) {
+	if r.lastPlayer == nil {
+		return
+	}
+	g.Players().ForEach(func(p *game.PlayerController) {
+		if p == r.lastPlayer || !card.IDInSlice(r.lastTile, r.waits(p)) {
+			return
+		}
+		if st := r.riichi[p.ID()]; st != nil {
+			st.furiten = true
+		}
+		r.furiten[p.ID()] = true
+	})
+}
+
+// Evaluate 算一次和牌的番数和符数，包括宝牌、里宝牌和赤宝牌
+// 荣和、抢杠时胡的那张牌也在和牌的玩家手里，一炮多响时每个和牌的玩家都算
+func (r *Rules) Evaluate(g *game.Game, w game.Win) (Result, error) {
+	h := r.newHand(g, w.Winner, w.Winner.Hand(), w.Tile, w.SelfDrawn, w.RobKong)
+	h.Dora = r.Dora(g)
+	h.RedFives = len(w.Winner.RedTiles())
+	if h.Riichi || h.DoubleRiichi {
+		h.UraDora = r.UraDora(g)
+	}
+	return Evaluate(h)
+}
+
+// newHand 和牌时的状况，不包括宝牌
+func (r *Rules) newHand(g *game.Game, player *game.PlayerController, hand []card.ID, winTile card.ID, selfDrawn, robKong bool) Hand {
+	h := Hand{
+		Tiles:     hand,
+		Melds:     player.GetShowCard(),
+		WinTile:   winTile,
+		SelfDrawn: selfDrawn,
+		Dealer:    player == r.dealer,
+		SeatWind:  r.winds[player.ID()],
+		RoundWind: r.RoundWind,
+		LastDraw:  g.Deck().NoTiles(),
+		KongDraw:  selfDrawn && totalKongs(g, player) > r.kongs[player.ID()],
+		RobKong:   robKong,
+		FirstDraw: selfDrawn && len(g.Pile().Discards(player)) == 0 && totalMelds(g) == 0,
+	}
+	if st := r.riichi[player.ID()]; st != nil {
+		// 上次出牌之后有人鸣牌或者杠了（包括暗杠、补杠）一发就没了，杠之后岭上开花也不算一发
+		if meldsAndKongs(g) != r.melds {
+			st.ippatsu = false
+		}
+		h.Riichi, h.DoubleRiichi, h.Ippatsu = !st.double, st.double, st.ippatsu
+	}
+	return h
+}
+
+// Score 结算
+// 立直的玩家交 1000 点立直棒，第一个和牌的玩家拿走场上所有的立直棒
+// 荣和时放铳的玩家一个人付，自摸时其他玩家按庄闲分别付，本场数只加给第一个和牌的玩家
+// 流局时没听牌的玩家一共赔给听牌的玩家 3000 点（三人麻将 2000 点），立直棒留到下一局
+func (r *Rules) Score(g *game.Game, result *game.HandResult) (map[int]int, error) {
+	scores := make(map[int]int)
+	sticks := 0
+	for id := range r.riichi {
+		if r.pending != nil && r.pending.ID() == id && len(result.Wins) > 0 &&
+			result.Wins[0].Discarder == r.pending && !result.Wins[0].RobKong {
+			// 宣言立直打出的牌被荣和，立直不成立
+			continue
+		}
+		scores[id] -= riichiStick
+		sticks++
+	}
+
+	for i, w := range result.Wins {
+		res, err := r.Evaluate(g, w)
+		if err != nil {
+			return nil, err
+		}
+		honba := 0
+		if i == 0 {
+			honba = r.Honba
+			scores[w.Winner.ID()] += riichiStick * (r.Sticks + sticks)
+		}
+		dealer := w.Winner == r.dealer
+		if !w.SelfDrawn {
+			points := res.RonPoints(dealer) + honbaPoints*honba
+			scores[w.Discarder.ID()] -= points
+			scores[w.Winner.ID()] += points
+			continue
+		}
+		fromDealer, fromOthers := res.TsumoPoints(dealer)
+		g.Players().ForEach(func(p *game.PlayerController) {
+			if p == w.Winner {
+				return
+			}
+			points := fromOthers
+			if p == r.dealer {
+				points = fromDealer
+			}
+			points += honbaPoints / 3 * honba
+			scores[p.ID()] -= points
+			scores[w.Winner.ID()] += points
+		})
+	}
+	if len(result.Wins) > 0 {
+		r.Sticks = 0
+		return scores, nil
+	}
+
+	r.Sticks += sticks
+	var tenpai, noten []*game.PlayerController
+	g.Players().ForEach(func(p *game.PlayerController) {
+		if len(r.waits(p)) > 0 {
+			tenpai = append(tenpai, p)
+		} else {
+			noten = append(noten, p)
+		}
+	})
+	if len(tenpai) == 0 || len(noten) == 0 {
+		return scores, nil
+	}
+	penalty := notenPenalty * (g.Players().Len() - 1)
+	for _, p := range tenpai {
+		scores[p.ID()] += penalty / len(tenpai)
+	}
+	for _, p := range noten {
+		scores[p.ID()] -= penalty / len(noten)
+	}
+	return scores, nil
+}
+
+// waits 听的牌
+func (r *Rules) waits(player *game.PlayerController) []card.ID {
+	_, waits := ting.CanTingWith(player.Hand(), player.GetShowCardTiles(), r.WinOptions())
+	return waits
+}
+
+// doraOf 指示牌的下一张是宝牌，见 card.NextTile
+func doraOf(indicators []card.ID) []card.ID {
+	dora := make([]card.ID, 0, len(indicators))
+	for _, t := range indicators {
+		dora = append(dora, card.NextTile(t))
+	}
+	return dora
+}
+
+// totalKongs 杠的组数，不传玩家时统计所有玩家
+func totalKongs(g *game.Game, players ...*game.PlayerController) int {
+	n := 0
+	count := func(p *game.PlayerController) {
+		for _, sc := range p.GetShowCard() {
+			if sc.GetTilesLen() == 4 {
+				n++
+			}
+		}
+	}
+	if len(players) > 0 {
+		for _, p := range players {
+			count(p)
+		}
+		return n
+	}
+	g.Players().ForEach(count)
+	return n
+}
+
+// isKonged 有没有玩家杠了这张牌
+func isKonged(g *game.Game, tile card.ID) bool {
+	konged := false
+	g.Players().ForEach(func(p *game.PlayerController) {
+		for _, sc := range p.GetShowCard() {
+			konged = konged || (sc.GetTilesLen() == 4 && sc.GetTile() == tile)
+		}
+	})
+	return konged
+}
+
+// totalMelds 所有玩家明牌的组数，包括暗杠
+func totalMelds(g *game.Game) int {
+	n := 0
+	g.Players().ForEach(func(p *game.PlayerController) {
+		n += len(p.GetShowCard())
+	})
+	return n
+}
+
+// meldsAndKongs 所有玩家明牌的组数加上杠的组数，补杠时明牌的组数不变，杠的组数会变
+func meldsAndKongs(g *game.Game) int {
+	return totalMelds(g) + totalKongs(g)
+}
+
+// isOpened 有没有吃、碰、明杠，暗杠不算
+func isOpened(player *game.PlayerController) bool {
+	for _, sc := range player.GetShowCard() {
+		if sc.IsShow() && sc.GetOpCode() != consts.AN_GANG {
+			return true
+		}
+	}
+	return false
+}
+
+func sameTiles(a, b []card.ID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,377 @@
+package riichi
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+	"github.com/mikodream/mahjong/game"
+	"github.com/mikodream/mahjong/ting"
+)
+
+// testPlayer 听牌就立直，能和就和，能碰就碰，立直后不能碰、只能摸切由规则保证
+type testPlayer struct {
+	id int
+}
+
+func (p *testPlayer) PlayerID() int {
+	return p.id
+}
+
+func (p *testPlayer) NickName() string {
+	return string(rune('A' + p.id))
+}
+
+func (p *testPlayer) DeclareRiichi(tiles []card.ID, gameState game.State) (bool, error) {
+	return true, nil
+}
+
+func (p *testPlayer) Exchange(tiles []card.ID, gameState game.State) ([]card.ID, error) {
+	return tiles[:3], nil
+}
+
+func (p *testPlayer) Play(tiles []card.ID, gameState game.State) (card.ID, error) {
+	for discard := range ting.GetTingMap(tiles, nil) {
+		return discard, nil
+	}
+	best, bestScore := tiles[0], 100
+	for _, t := range tiles {
+		score := 0
+		for _, o := range tiles {
+			if d := int(o) - int(t); d >= -2 && d <= 2 {
+				score++
+			}
+		}
+		if score < bestScore {
+			best, bestScore = t, score
+		}
+	}
+	return best, nil
+}
+
+func (p *testPlayer) Take(tiles []card.ID, gameState game.State) (int, []card.ID, error) {
+	top := gameState.LastPlayedTile
+	for _, c := range gameState.CanWin {
+		if c.ID() == p.id {
+			return consts.WIN, []card.ID{top}, nil
+		}
+	}
+	for _, op := range gameState.SpecialPrivileges[p.id] {
+		if op == consts.PENG {
+			return consts.PENG, []card.ID{top, top, top}, nil
+		}
+	}
+	return 0, nil, nil
+}
+
+func (p *testPlayer) Act(tiles []card.ID, gameState game.State) (int, card.ID, error) {
+	for _, op := range gameState.SpecialPrivileges[p.id] {
+		if op == consts.WIN {
+			return consts.WIN, 0, nil
+		}
+	}
+	return 0, 0, nil
+}
+
+func TestEvaluate(t *testing.T) {
+	pung := game.NewShowCard(consts.PENG, 0, []card.ID{15, 15, 15}, true, false)
+	cases := []struct {
+		name string
+		hand Hand
+		yaku []Yaku
+		han  int
+		fu   int
+		err  error
+	}{
+		{
+			name: "门前清自摸、平和、断幺九",
+			hand: Hand{Tiles: []card.ID{2, 3, 4, 5, 6, 7, 22, 23, 24, 16, 17, 18, 12, 12}, WinTile: 2, SelfDrawn: true},
+			yaku: []Yaku{MenzenTsumo, Pinfu, Tanyao}, han: 3, fu: 20,
+		},
+		{
+			name: "七对子",
+			hand: Hand{Tiles: []card.ID{1, 1, 3, 3, 5, 5, 7, 7, 11, 11, 13, 13, 33, 33}, WinTile: 33},
+			yaku: []Yaku{Chiitoitsu}, han: 2, fu: 25,
+		},
+		{
+			name: "立直、坎张、幺九暗刻",
+			hand: Hand{Tiles: []card.ID{1, 1, 1, 22, 23, 24, 5, 6, 7, 15, 16, 17, 29, 29}, WinTile: 23, Riichi: true},
+			yaku: []Yaku{Riichi}, han: 1, fu: 40,
+		},
+		{
+			name: "立直、一发、宝牌、里宝牌、赤宝牌",
+			hand: Hand{
+				Tiles: []card.ID{2, 3, 4, 5, 5, 5, 22, 23, 24, 16, 17, 18, 29, 29}, WinTile: 29,
+				Riichi: true, Ippatsu: true, Dora: []card.ID{5}, UraDora: []card.ID{29}, RedFives: 1,
+			},
+			yaku: []Yaku{Riichi, Ippatsu}, han: 2 + 3 + 2 + 1, fu: 40,
+		},
+		{
+			name: "副露没有役",
+			hand: Hand{Tiles: []card.ID{1, 2, 3, 11, 12, 13, 22, 23, 24, 29, 29}, Melds: []*game.ShowCard{pung}, WinTile: 29, Dora: []card.ID{15}},
+			err:  ErrNoYaku,
+		},
+		{
+			name: "不是和牌牌型",
+			hand: Hand{Tiles: []card.ID{1, 2, 4, 11, 12, 13, 21, 22, 23, 29, 29, 31, 31, 31}, WinTile: 4},
+			err:  ErrNotWin,
+		},
+		{
+			name: "大三元",
+			hand: Hand{Tiles: []card.ID{41, 41, 41, 42, 42, 42, 43, 43, 43, 1, 2, 3, 5, 5}, WinTile: 5},
+			yaku: []Yaku{Daisangen},
+		},
+		{
+			name: "国士无双",
+			hand: Hand{Tiles: []card.ID{1, 9, 11, 19, 21, 29, 31, 32, 33, 34, 41, 42, 43, 43}, WinTile: 1},
+			yaku: []Yaku{KokushiMusou},
+		},
+		{
+			name: "清一色、一气通贯（副露减一番）",
+			hand: Hand{
+				Tiles:   []card.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 9, 9},
+				Melds:   []*game.ShowCard{game.NewShowCard(consts.CHI, 0, []card.ID{2, 3, 4}, true, false)},
+				WinTile: 9,
+			},
+			yaku: []Yaku{Ittsu, Chinitsu}, han: 1 + 5, fu: 30,
+		},
+	}
+	for _, c := range cases {
+		r, err := Evaluate(c.hand)
+		if err != c.err {
+			t.Errorf("%s: 期望错误 %v, 实际 %v", c.name, c.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(r.Yaku, c.yaku) || r.Han != c.han || r.Fu != c.fu {
+			t.Errorf("%s: 期望 %v %d 番 %d 符, 实际 %v %d 番 %d 符", c.name, c.yaku, c.han, c.fu, r.Yaku, r.Han, r.Fu)
+		}
+	}
+}
+
+func TestPoints(t *testing.T) {
+	cases := []struct {
+		result Result
+		dealer bool
+		ron    int
+		limit  Limit
+	}{
+		{Result{Han: 1, Fu: 30}, false, 1000, NoLimit},
+		{Result{Han: 3, Fu: 30}, false, 3900, NoLimit},
+		{Result{Han: 4, Fu: 30}, false, 7700, NoLimit},
+		{Result{Han: 4, Fu: 30}, true, 11600, NoLimit},
+		{Result{Han: 4, Fu: 40}, false, 8000, Mangan},
+		{Result{Han: 6, Fu: 30}, false, 12000, Haneman},
+		{Result{Han: 8, Fu: 30}, false, 16000, Baiman},
+		{Result{Han: 11, Fu: 30}, false, 24000, Sanbaiman},
+		{Result{Han: 13, Fu: 30}, true, 48000, Yakuman},
+		{Result{Yakuman: 2}, false, 64000, Yakuman},
+	}
+	for i, c := range cases {
+		if ron := c.result.RonPoints(c.dealer); ron != c.ron {
+			t.Errorf("case %d 荣和点数错误: 期望 %d, 实际 %d", i, c.ron, ron)
+		}
+		if l := c.result.Limit(); l != c.limit {
+			t.Errorf("case %d 档位错误: 期望 %v, 实际 %v", i, c.limit, l)
+		}
+	}
+
+	fromDealer, fromOthers := Result{Han: 3, Fu: 20}.TsumoPoints(false)
+	if fromDealer != 1300 || fromOthers != 700 {
+		t.Errorf("20 符 3 番闲家自摸应该是 700/1300, 实际 %d/%d", fromOthers, fromDealer)
+	}
+	if _, fromOthers = (Result{Han: 5}).TsumoPoints(true); fromOthers != 4000 {
+		t.Errorf("庄家满贯自摸每家应该付 4000, 实际 %d", fromOthers)
+	}
+}
+
+func TestDoraOf(t *testing.T) {
+	indicators := []card.ID{9, 15, card.MAHJONG_NORTH, card.MAHJONG_EAST, card.MAHJONG_RED, card.MAHJONG_WHITE}
+	expected := []card.ID{1, 16, card.MAHJONG_EAST, card.MAHJONG_SOUTH, card.MAHJONG_WHITE, card.MAHJONG_GREE}
+	if dora := doraOf(indicators); !reflect.DeepEqual(dora, expected) {
+		t.Errorf("宝牌错误: 期望 %v, 实际 %v", expected, dora)
+	}
+}
+
+func TestRun(t *testing.T) {
+	for i := 0; i < 30; i++ {
+		rules := New()
+		players := make([]game.Player, 0, 4)
+		for id := 0; id < 4; id++ {
+			players = append(players, &testPlayer{id: id})
+		}
+		g := game.New(players, game.WithRuleSet(rules))
+		result, err := g.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+
+		for id, st := range rules.riichi {
+			p := g.Players().GetPlayerController(id)
+			if isOpened(p) {
+				t.Errorf("%s 立直后鸣牌: %v", p.Name(), p.GetShowCard())
+			}
+			if len(p.Hand())%3 == 1 && !sameTiles(rules.waits(p), st.waits) {
+				t.Errorf("%s 立直后改变了听的牌: %v => %v", p.Name(), st.waits, rules.waits(p))
+			}
+		}
+
+		reds := make(map[card.ID]int)
+		g.Players().ForEach(func(p *game.PlayerController) {
+			for _, t := range p.RedTiles() {
+				reds[t]++
+			}
+		})
+		for five, n := range reds {
+			if five.Rank() != 5 || n > 1 {
+				t.Errorf("每门花色只有一张赤 5: %v", reds)
+			}
+		}
+
+		total := 0
+		for _, s := range result.Scores {
+			total += s
+		}
+		if result.Exhausted {
+			if total != -riichiStick*rules.Sticks {
+				t.Errorf("流局时立直棒应该留在场上: 输赢 %v, 立直棒 %d", result.Scores, rules.Sticks)
+			}
+			continue
+		}
+		if total != 0 || rules.Sticks != 0 {
+			t.Errorf("和牌时输赢加起来应该为 0: %v", result.Scores)
+		}
+		for _, w := range result.Wins {
+			if _, err := rules.Evaluate(g, w); err != nil {
+				t.Errorf("%s 和了没有役的牌: %v %v", w.Winner.Name(), w.Winner.Hand(), err)
+			}
+			if !w.SelfDrawn && card.IDInSlice(w.Tile, g.Pile().Discards(w.Winner)) {
+				t.Errorf("%s 振听荣和: %v", w.Winner.Name(), w.Tile)
+			}
+		}
+	}
+}
+
+func TestScoreError(t *testing.T) {
+	rules := New()
+	players := make([]game.Player, 0, 4)
+	for id := 0; id < 4; id++ {
+		players = append(players, &testPlayer{id: id})
+	}
+	g := game.New(players, game.WithRuleSet(rules))
+	g.DealStartingTiles()
+	if err := rules.AfterDeal(context.Background(), g); err != nil {
+		t.Fatalf("AfterDeal error: %v", err)
+	}
+	// 起手的 13 张不是和牌牌型
+	winner := g.Players().Peek()
+	result := &game.HandResult{Wins: []game.Win{{Winner: winner, Tile: winner.LastTile(), SelfDrawn: true}}}
+	if scores, err := rules.Score(g, result); err != ErrNotWin {
+		t.Errorf("算不出和牌的点数时应该返回 ErrNotWin, 实际 %v %v", scores, err)
+	}
+}
+
+func TestIppatsuAfterKong(t *testing.T) {
+	rules := New()
+	players := make([]game.Player, 0, 4)
+	for id := 0; id < 4; id++ {
+		players = append(players, &testPlayer{id: id})
+	}
+	g := game.New(players, game.WithRuleSet(rules))
+	g.DealStartingTiles()
+	if err := rules.AfterDeal(context.Background(), g); err != nil {
+		t.Fatalf("AfterDeal error: %v", err)
+	}
+	player := g.Players().Peek()
+	rules.riichi[player.ID()] = &riichiState{ippatsu: true}
+	if h := rules.newHand(g, player, player.Hand(), player.LastTile(), true, false); !h.Ippatsu {
+		t.Fatalf("立直之后没有人鸣牌时应该是一发")
+	}
+	// 立直的玩家暗杠之后岭上开花不算一发
+	player.DarkGang(card.MAHJONG_EAST)
+	if h := rules.newHand(g, player, player.Hand(), player.LastTile(), true, false); h.Ippatsu {
+		t.Errorf("暗杠之后不应该是一发")
+	}
+}
+
+func TestSanma(t *testing.T) {
+	if dora := NewSanma().dora([]card.ID{card.MAHJONG_CRAK1, card.MAHJONG_CRAK9}); !reflect.DeepEqual(dora, []card.ID{card.MAHJONG_CRAK9, card.MAHJONG_CRAK1}) {
+		t.Errorf("三麻一万的下一张应该是九万: %v", dora)
+	}
+	for i := 0; i < 20; i++ {
+		rules := NewSanma()
+		players := make([]game.Player, 0, 3)
+		for id := 0; id < 3; id++ {
+			players = append(players, &testPlayer{id: id})
+		}
+		g := game.New(players, game.WithRuleSet(rules))
+		result, err := g.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+		total := 0
+		for _, s := range result.Scores {
+			total += s
+		}
+		if !result.Exhausted && total != 0 {
+			t.Errorf("和牌时输赢加起来应该为 0: %v", result.Scores)
+		}
+		if result.Exhausted {
+			paid := 0
+			for id, s := range result.Scores {
+				if rules.riichi[id] != nil {
+					s += riichiStick
+				}
+				if s > 0 {
+					paid += s
+				}
+			}
+			if paid != 0 && paid != 2000 {
+				t.Errorf("三麻流局罚符一共应该是 2000 点: %v", result.Scores)
+			}
+		}
+		g.Players().ForEach(func(p *game.PlayerController) {
+			for _, sc := range p.GetShowCard() {
+				if sc.GetOpCode() == consts.CHI {
+					t.Errorf("三麻不能吃: %v", sc)
+				}
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	players := make([]game.Player, 0, 4)
+	var rules []*Rules
+	factory := MatchRules(New())
+	newRules := func(hand game.HandInfo, prev game.RuleSet) game.RuleSet {
+		r := factory(hand, prev).(*Rules)
+		if r.RoundWind != hand.RoundWind || r.Honba != hand.Honba {
+			t.Errorf("场风、本场数和局况不一致: %+v", hand)
+		}
+		if prev != nil && r.Sticks != prev.(*Rules).Sticks {
+			t.Errorf("立直棒应该留到下一局: %d => %d", prev.(*Rules).Sticks, r.Sticks)
+		}
+		rules = append(rules, r)
+		return r
+	}
+	for id := 0; id < 4; id++ {
+		players = append(players, &testPlayer{id: id})
+	}
+	m := game.NewMatch(players, newRules, game.WithRounds(2), game.WithStartScore(25000), game.WithBust())
+	scores, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	total := 0
+	for _, s := range scores {
+		total += s
+	}
+	if sticks := rules[len(rules)-1].Sticks; total+riichiStick*sticks != 4*25000 {
+		t.Errorf("点数加上场上的立直棒应该是 100000: %v, 立直棒 %d", scores, sticks)
+	}
+}
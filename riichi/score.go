@@ -0,0 +1,76 @@
+package riichi
+
+// Limit 满贯以上的点数档位
+type Limit int
+
+const (
+	NoLimit   Limit = iota // 按符数和番数计算
+	Mangan                 // 满贯：5 番，或者基本点超过 2000
+	Haneman                // 跳满：6、7 番
+	Baiman                 // 倍满：8 ~ 10 番
+	Sanbaiman              // 三倍满：11、12 番
+	Yakuman                // 役满，13 番以上算累计役满
+)
+
+var limitNames = [...]string{"", "满贯", "跳满", "倍满", "三倍满", "役满"}
+
+func (l Limit) String() string {
+	if l < 0 || int(l) >= len(limitNames) {
+		return ""
+	}
+	return limitNames[l]
+}
+
+// limitBase 每个档位的基本点
+var limitBase = [...]int{0, 2000, 3000, 4000, 6000, 8000}
+
+// Limit 这次和牌的档位
+func (r Result) Limit() Limit {
+	switch {
+	case r.Yakuman > 0 || r.Han >= 13:
+		return Yakuman
+	case r.Han >= 11:
+		return Sanbaiman
+	case r.Han >= 8:
+		return Baiman
+	case r.Han >= 6:
+		return Haneman
+	case r.Han >= 5 || r.Fu<<(2+r.Han) > limitBase[Mangan]:
+		return Mangan
+	}
+	return NoLimit
+}
+
+// BasePoints 基本点：符数 × 2^(番数+2)，满贯以上按档位，役满按倍数
+func (r Result) BasePoints() int {
+	switch l := r.Limit(); {
+	case r.Yakuman > 0:
+		return limitBase[Yakuman] * r.Yakuman
+	case l != NoLimit:
+		return limitBase[l]
+	}
+	return r.Fu << (2 + r.Han)
+}
+
+// RonPoints 荣和时放铳的玩家支付的点数，庄家和了是基本点的 6 倍，闲家是 4 倍
+func (r Result) RonPoints(dealer bool) int {
+	if dealer {
+		return roundUp(r.BasePoints() * 6)
+	}
+	return roundUp(r.BasePoints() * 4)
+}
+
+// TsumoPoints 自摸时庄家和其他闲家各自支付的点数
+// 庄家自摸时每家支付基本点的 2 倍，fromDealer 为 0；闲家自摸时庄家支付 2 倍，其他闲家支付 1 倍
+func (r Result) TsumoPoints(dealer bool) (fromDealer, fromOthers int) {
+	base := r.BasePoints()
+	if dealer {
+		return 0, roundUp(base * 2)
+	}
+	return roundUp(base * 2), roundUp(base)
+}
+
+// roundUp 点数进位到 100
+func roundUp(points int) int {
+	return (points + 99) / 100 * 100
+}
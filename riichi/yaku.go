@@ -0,0 +1,98 @@
+package riichi
+
+// Yaku 役种
+type Yaku int
+
+const (
+	Riichi         Yaku = iota // 立直
+	DoubleRiichi               // 两立直
+	Ippatsu                    // 一发
+	MenzenTsumo                // 门前清自摸和
+	Pinfu                      // 平和
+	Tanyao                     // 断幺九
+	Iipeikou                   // 一杯口
+	Haku                       // 役牌 白
+	Hatsu                      // 役牌 发
+	Chun                       // 役牌 中
+	SeatWind                   // 自风
+	RoundWind                  // 场风
+	Haitei                     // 海底摸月
+	Houtei                     // 河底捞鱼
+	Rinshan                    // 岭上开花
+	Chankan                    // 抢杠
+	Chiitoitsu                 // 七对子
+	Chanta                     // 混全带幺九
+	Ittsu                      // 一气通贯
+	SanshokuDoujun             // 三色同顺
+	SanshokuDoukou             // 三色同刻
+	Sanankou                   // 三暗刻
+	Sankantsu                  // 三杠子
+	Toitoi                     // 对对和
+	Honroutou                  // 混老头
+	Shousangen                 // 小三元
+	Honitsu                    // 混一色
+	Junchan                    // 纯全带幺九
+	Ryanpeikou                 // 二杯口
+	Chinitsu                   // 清一色
+
+	KokushiMusou  // 国士无双
+	Suuankou      // 四暗刻
+	Daisangen     // 大三元
+	Shousuushii   // 小四喜
+	Daisuushii    // 大四喜
+	Tsuuiisou     // 字一色
+	Ryuuiisou     // 绿一色
+	Chinroutou    // 清老头
+	ChuurenPoutou // 九莲宝灯
+	Suukantsu     // 四杠子
+	Tenhou        // 天和
+	Chiihou       // 地和
+
+	yakuCount
+)
+
+var yakuNames = [yakuCount]string{
+	"立直", "两立直", "一发", "门前清自摸和", "平和", "断幺九", "一杯口",
+	"役牌 白", "役牌 发", "役牌 中", "自风", "场风",
+	"海底摸月", "河底捞鱼", "岭上开花", "抢杠",
+	"七对子", "混全带幺九", "一气通贯", "三色同顺", "三色同刻", "三暗刻", "三杠子", "对对和", "混老头", "小三元",
+	"混一色", "纯全带幺九", "二杯口", "清一色",
+	"国士无双", "四暗刻", "大三元", "小四喜", "大四喜", "字一色", "绿一色", "清老头", "九莲宝灯", "四杠子", "天和", "地和",
+}
+
+// yakuHan 门前清时的番数和副露时的番数，副露时为 0 表示必须门前清
+var yakuHan = [Chinitsu + 1][2]int{
+	Riichi: {1, 0}, DoubleRiichi: {2, 0}, Ippatsu: {1, 0}, MenzenTsumo: {1, 0}, Pinfu: {1, 0},
+	Tanyao: {1, 1}, Iipeikou: {1, 0},
+	Haku: {1, 1}, Hatsu: {1, 1}, Chun: {1, 1}, SeatWind: {1, 1}, RoundWind: {1, 1},
+	Haitei: {1, 1}, Houtei: {1, 1}, Rinshan: {1, 1}, Chankan: {1, 1},
+	Chiitoitsu: {2, 0}, Chanta: {2, 1}, Ittsu: {2, 1}, SanshokuDoujun: {2, 1}, SanshokuDoukou: {2, 2},
+	Sanankou: {2, 2}, Sankantsu: {2, 2}, Toitoi: {2, 2}, Honroutou: {2, 2}, Shousangen: {2, 2},
+	Honitsu: {3, 2}, Junchan: {3, 2}, Ryanpeikou: {3, 0}, Chinitsu: {6, 5},
+}
+
+func (y Yaku) String() string {
+	if y < 0 || y >= yakuCount {
+		return "未知役种"
+	}
+	return yakuNames[y]
+}
+
+// IsYakuman 是否役满
+func (y Yaku) IsYakuman() bool {
+	return y >= KokushiMusou && y < yakuCount
+}
+
+// Han 番数，opened 表示副露（暗杠不算），役满返回 13
+// 必须门前清的役种副露时返回 0
+func (y Yaku) Han(opened bool) int {
+	switch {
+	case y.IsYakuman():
+		return 13
+	case y < 0 || y >= yakuCount:
+		return 0
+	case opened:
+		return yakuHan[y][1]
+	}
+	return yakuHan[y][0]
+}
@@ -0,0 +1,183 @@
+package sichuan
+
+import (
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+	"github.com/mikodream/mahjong/game"
+	"github.com/mikodream/mahjong/ting"
+)
+
+// Fan 计算一次胡牌的番数，超过封顶时按封顶算
+func (r *Rules) Fan(w game.Win) int {
+	return r.capFan(fan(w.Winner.Hand(), w.Winner.GetShowCard(), w.SelfDrawn, w.RobKong))
+}
+
+// Score 结算
+// 胡牌的分数是 2 的番数次方，点炮的玩家一个人给，自摸时还没胡牌的玩家每人给
+// 牌墙摸完时还没胡牌的玩家查花猪、查叫：
+// 花猪（手里还有三门花色）赔给每个不是花猪的玩家封顶的分数，
+// 没听牌的玩家赔给每个听牌的玩家他能胡的最大番数
+func (r *Rules) Score(g *game.Game, result *game.HandResult) (map[int]int, error) {
+	scores := make(map[int]int)
+	won := make(map[int]bool)
+	for _, w := range result.Wins {
+		points := 1 << r.Fan(w)
+		if w.SelfDrawn {
+			g.Players().ForEach(func(p *game.PlayerController) {
+				if p.ID() != w.Winner.ID() && !won[p.ID()] {
+					scores[p.ID()] -= points
+					scores[w.Winner.ID()] += points
+				}
+			})
+		} else {
+			scores[w.Discarder.ID()] -= points
+			scores[w.Winner.ID()] += points
+		}
+		won[w.Winner.ID()] = true
+	}
+	if !result.Exhausted {
+		return scores, nil
+	}
+
+	var pigs, notReady []*game.PlayerController
+	ready := make(map[*game.PlayerController]int)
+	g.Players().ForEach(func(p *game.PlayerController) {
+		if won[p.ID()] {
+			return
+		}
+		if isFlowerPig(p) {
+			pigs = append(pigs, p)
+		} else if maxFan, ok := r.readyFan(g, p); ok {
+			ready[p] = maxFan
+		} else {
+			notReady = append(notReady, p)
+		}
+	})
+
+	capFan := r.MaxFan
+	if capFan == 0 {
+		capFan = defaultMaxFan
+	}
+	capPoints := 1 << capFan
+	for _, pig := range pigs {
+		g.Players().ForEach(func(p *game.PlayerController) {
+			if won[p.ID()] || isFlowerPig(p) {
+				return
+			}
+			scores[pig.ID()] -= capPoints
+			scores[p.ID()] += capPoints
+		})
+	}
+	for _, p := range notReady {
+		for q, maxFan := range ready {
+			scores[p.ID()] -= 1 << maxFan
+			scores[q.ID()] += 1 << maxFan
+		}
+	}
+	return scores, nil
+}
+
+// readyFan 查叫：玩家是否听牌，听牌时返回能胡的最大番数
+func (r *Rules) readyFan(g *game.Game, player *game.PlayerController) (int, bool) {
+	hand := player.Hand()
+	_, waits := ting.CanTingWith(hand, player.GetShowCardTiles(), r.WinOptions())
+	maxFan, ok := 0, false
+	for _, t := range waits {
+		withTile := append(append([]card.ID{}, hand...), t)
+		if !r.AllowWin(g, player, withTile) {
+			continue
+		}
+		ok = true
+		if f := r.capFan(fan(withTile, player.GetShowCard(), false, false)); f > maxFan {
+			maxFan = f
+		}
+	}
+	return maxFan, ok
+}
+
+func (r *Rules) capFan(f int) int {
+	if r.MaxFan > 0 && f > r.MaxFan {
+		return r.MaxFan
+	}
+	return f
+}
+
+// isFlowerPig 花猪：手牌和明牌里还有三门花色
+func isFlowerPig(player *game.PlayerController) bool {
+	suits := make(map[int]bool)
+	for _, t := range append(player.Hand(), player.GetShowCardTiles()...) {
+		suits[suitOf(t)] = true
+	}
+	return len(suits) == 3
+}
+
+// fan 胡牌的番数，hand 是包含胡的那张牌在内的手牌
+// 对对胡 1 番，清一色 2 番，七对 2 番，每个根（四张一样的牌）1 番，自摸 1 番，抢杠胡 1 番
+func fan(hand []card.ID, showCards []*game.ShowCard, selfDrawn, robKong bool) int {
+	counts := card.NewCMap()
+	counts.SetTiles(hand)
+	all := card.NewCMap()
+	all.SetTiles(hand)
+	for _, sc := range showCards {
+		all.SetTiles(sc.GetTiles())
+	}
+
+	f := 0
+	if len(showCards) == 0 && len(hand) == 14 && isSevenPairs(counts) {
+		f += 2
+	} else if isAllTriplets(counts, showCards) {
+		f++
+	}
+	if isOneSuit(all) {
+		f += 2
+	}
+	for _, n := range all.GetTileMap() {
+		if n == 4 {
+			f++
+		}
+	}
+	if selfDrawn {
+		f++
+	}
+	if robKong {
+		f++
+	}
+	return f
+}
+
+func isSevenPairs(counts *card.CMap) bool {
+	for _, n := range counts.GetTileMap() {
+		if n%2 != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// isAllTriplets 对对胡：明牌都是碰、杠，手牌是一对加若干刻子
+func isAllTriplets(counts *card.CMap, showCards []*game.ShowCard) bool {
+	for _, sc := range showCards {
+		if sc.GetOpCode() == consts.CHI {
+			return false
+		}
+	}
+	pairs := 0
+	for _, n := range counts.GetTileMap() {
+		switch n {
+		case 2:
+			pairs++
+		case 3:
+		default:
+			return false
+		}
+	}
+	return pairs == 1
+}
+
+func isOneSuit(all *card.CMap) bool {
+	suits := make(map[int]bool)
+	for t := range all.GetTileMap() {
+		suits[suitOf(t)] = true
+	}
+	return len(suits) == 1
+}
@@ -0,0 +1,151 @@
+package sichuan
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+	"github.com/mikodream/mahjong/game"
+	"github.com/mikodream/mahjong/tile"
+	"github.com/mikodream/mahjong/win"
+)
+
+// ErrInvalidSuit 定缺的花色不是万、条、饼
+var ErrInvalidSuit = errors.New("sichuan: invalid missing suit")
+
+// defaultMaxFan 默认封顶的番数
+const defaultMaxFan = 4
+
+// MissingSuitDecider 玩家自己选择定缺的花色（tile.WAN、tile.TIAO、tile.BING）
+// 没有实现这个接口的玩家自动缺手里最少的花色
+type MissingSuitDecider interface {
+	DeclareMissingSuit(tiles []card.ID, gameState game.State) (int, error)
+}
+
+// Rules 四川麻将血战到底
+// 108 张牌（没有字牌），不能吃，起手后定缺，没打完缺的花色不能胡
+// 一家胡了之后其他人接着打，直到三家胡牌或者牌墙摸完，摸完时查叫、查花猪
+// 开启 ExchangeThree 时定缺之前先换三张
+// 开启 TwoSuits 时是两房：去掉万子，只用条、饼 72 张，不定缺，三人麻将一般这样打
+// 每局牌的定缺记录在 Rules 中，所以每个 Game 要用一个新的 Rules
+type Rules struct {
+	game.BaseRules
+	MaxFan        int         // 封顶番数，0 表示不封顶（查花猪时按 4 番算）
+	ExchangeThree bool        // 换三张
+	TwoSuits      bool        // 两房
+	missing       map[int]int // 玩家 ID => 定缺的花色
+}
+
+// New 创建血战到底规则，一炮多响，4 番封顶
+func New() *Rules {
+	return &Rules{
+		BaseRules: game.BaseRules{AllowMultiRon: true},
+		MaxFan:    defaultMaxFan,
+		missing:   make(map[int]int),
+	}
+}
+
+// NewThreePlayer 创建三人两房规则，一炮多响，4 番封顶，两家胡牌之后结束
+func NewThreePlayer() *Rules {
+	r := New()
+	r.Players = 3
+	r.TwoSuits = true
+	return r
+}
+
+func (r *Rules) Tiles() []card.ID {
+	if r.TwoSuits {
+		return card.MahjongCards72
+	}
+	return card.MahjongCards108
+}
+
+func (r *Rules) AllowMeld(op int) bool {
+	return op != consts.CHI
+}
+
+func (r *Rules) WinOptions() win.Options {
+	return win.Options{SevenPairs: true}
+}
+
+// AllowWin 手牌和明牌里都没有定缺的花色才能胡
+func (r *Rules) AllowWin(g *game.Game, player *game.PlayerController, hand []card.ID) bool {
+	missing, ok := r.missing[player.ID()]
+	if !ok {
+		return true
+	}
+	return !hasSuit(hand, missing) && !hasSuit(player.GetShowCardTiles(), missing)
+}
+
+// AfterDeal 换三张、定缺
+func (r *Rules) AfterDeal(ctx context.Context, g *game.Game) error {
+	r.missing = make(map[int]int)
+	if r.ExchangeThree {
+		if _, err := g.ExchangeThree(); err != nil {
+			return err
+		}
+	}
+	if r.TwoSuits {
+		return nil
+	}
+	var err error
+	g.Players().ForEach(func(player *game.PlayerController) {
+		if err != nil {
+			return
+		}
+		suit := fewestSuit(player.Hand())
+		if decider, ok := player.Player().(MissingSuitDecider); ok {
+			suit, err = decider.DeclareMissingSuit(player.Hand(), g.ExtractState(player))
+			if err == nil && suit != tile.WAN && suit != tile.TIAO && suit != tile.BING {
+				err = ErrInvalidSuit
+			}
+		}
+		r.missing[player.ID()] = suit
+	})
+	return err
+}
+
+// MissingSuit 玩家定缺的花色
+func (r *Rules) MissingSuit(player *game.PlayerController) int {
+	return r.missing[player.ID()]
+}
+
+// HandOver 三家胡牌之后才结束
+func (r *Rules) HandOver(g *game.Game, result *game.HandResult) bool {
+	winners := make(map[int]bool)
+	for _, w := range result.Wins {
+		winners[w.Winner.ID()] = true
+	}
+	return len(winners) >= g.Players().Len()-1
+}
+
+// suitOf 牌的花色，和 tile.WAN、tile.TIAO、tile.BING 对应
+func suitOf(t card.ID) int {
+	return tile.Tile(t).Type()
+}
+
+// fewestSuit 手里张数最少的花色
+func fewestSuit(hand []card.ID) int {
+	counts := map[int]int{tile.WAN: 0, tile.TIAO: 0, tile.BING: 0}
+	for _, t := range hand {
+		counts[suitOf(t)]++
+	}
+	suit := tile.WAN
+	for _, s := range []int{tile.TIAO, tile.BING} {
+		if counts[s] < counts[suit] {
+			suit = s
+		}
+	}
+	return suit
+}
+
+// hasSuit 牌里有没有这个花色
+func hasSuit(tiles []card.ID, suit int) bool {
+	for _, t := range tiles {
+		if suitOf(t) == suit {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package sichuan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+	"github.com/mikodream/mahjong/game"
+	"github.com/mikodream/mahjong/tile"
+	"github.com/mikodream/mahjong/ting"
+)
+
+// testPlayer 先打缺的花色，能胡就胡，能碰就碰
+type testPlayer struct {
+	id      int
+	missing int
+}
+
+func (p *testPlayer) PlayerID() int {
+	return p.id
+}
+
+func (p *testPlayer) NickName() string {
+	return string(rune('A' + p.id))
+}
+
+func (p *testPlayer) DeclareMissingSuit(tiles []card.ID, gameState game.State) (int, error) {
+	p.missing = fewestSuit(tiles)
+	return p.missing, nil
+}
+
+// Exchange 换出最少的、至少有三张的花色
+func (p *testPlayer) Exchange(tiles []card.ID, gameState game.State) ([]card.ID, error) {
+	suits := make(map[int][]card.ID)
+	for _, t := range tiles {
+		suits[suitOf(t)] = append(suits[suitOf(t)], t)
+	}
+	best := -1
+	for suit, ts := range suits {
+		if len(ts) >= 3 && (best < 0 || len(ts) < len(suits[best])) {
+			best = suit
+		}
+	}
+	return suits[best][:3], nil
+}
+
+func (p *testPlayer) Play(tiles []card.ID, gameState game.State) (card.ID, error) {
+	for _, t := range tiles {
+		if suitOf(t) == p.missing {
+			return t, nil
+		}
+	}
+	for discard := range ting.GetTingMap(tiles, nil) {
+		return discard, nil
+	}
+	return tiles[len(tiles)-1], nil
+}
+
+func (p *testPlayer) Take(tiles []card.ID, gameState game.State) (int, []card.ID, error) {
+	top := gameState.LastPlayedTile
+	for _, c := range gameState.CanWin {
+		if c.ID() == p.id {
+			return consts.WIN, []card.ID{top}, nil
+		}
+	}
+	for _, op := range gameState.SpecialPrivileges[p.id] {
+		if op == consts.PENG && suitOf(top) != p.missing {
+			return consts.PENG, []card.ID{top, top, top}, nil
+		}
+	}
+	return 0, nil, nil
+}
+
+func (p *testPlayer) Act(tiles []card.ID, gameState game.State) (int, card.ID, error) {
+	for _, op := range gameState.SpecialPrivileges[p.id] {
+		if op == consts.WIN {
+			return consts.WIN, 0, nil
+		}
+	}
+	return 0, 0, nil
+}
+
+func TestFan(t *testing.T) {
+	cases := []struct {
+		hand      []card.ID
+		selfDrawn bool
+		fan       int
+	}{
+		// 平胡
+		{[]card.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 11, 12, 13, 15, 15}, false, 0},
+		// 平胡自摸
+		{[]card.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 11, 12, 13, 15, 15}, true, 1},
+		// 清一色
+		{[]card.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 1, 2, 3, 5, 5}, false, 2},
+		// 清一色对对胡
+		{[]card.ID{1, 1, 1, 3, 3, 3, 5, 5, 5, 7, 7, 7, 9, 9}, false, 3},
+		// 七对
+		{[]card.ID{1, 1, 3, 3, 5, 5, 7, 7, 11, 11, 13, 13, 15, 15}, false, 2},
+		// 龙七对（七对 + 根）
+		{[]card.ID{1, 1, 1, 1, 5, 5, 7, 7, 11, 11, 13, 13, 15, 15}, false, 3},
+	}
+	for i, c := range cases {
+		if f := fan(c.hand, nil, c.selfDrawn, false); f != c.fan {
+			t.Errorf("case %d 番数错误: 期望 %d, 实际 %d", i, c.fan, f)
+		}
+	}
+
+	r := New()
+	if f := r.capFan(fan([]card.ID{1, 1, 1, 1, 3, 3, 5, 5, 7, 7, 9, 9, 2, 2}, nil, true, true)); f != r.MaxFan {
+		t.Errorf("超过封顶应该按 %d 番算, 实际 %d", r.MaxFan, f)
+	}
+}
+
+func TestBloodyBattle(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		players := make([]game.Player, 0, 4)
+		for id := 0; id < 4; id++ {
+			players = append(players, &testPlayer{id: id})
+		}
+		rules := New()
+		rules.ExchangeThree = i%2 == 0
+		g := game.New(players, game.WithRuleSet(rules))
+		result, err := g.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+
+		winners := make(map[int]bool)
+		for _, w := range result.Wins {
+			if winners[w.Winner.ID()] {
+				t.Errorf("%s 胡了两次", w.Winner.Name())
+			}
+			winners[w.Winner.ID()] = true
+			if hasSuit(w.Winner.Tiles(), rules.MissingSuit(w.Winner)) {
+				t.Errorf("%s 没打完缺的花色就胡了: %v", w.Winner.Name(), w.Winner.Tiles())
+			}
+		}
+		if !result.Exhausted && len(winners) != 3 {
+			t.Errorf("没有摸完时应该三家胡牌才结束: %d", len(winners))
+		}
+
+		total := 0
+		g.Players().ForEach(func(p *game.PlayerController) {
+			for _, sc := range p.GetShowCard() {
+				if sc.GetOpCode() == consts.CHI {
+					t.Errorf("血战到底不能吃: %v", sc)
+				}
+			}
+			total += result.Scores[p.ID()]
+		})
+		if total != 0 {
+			t.Errorf("输赢分加起来应该为 0: %v", result.Scores)
+		}
+	}
+}
+
+func TestThreePlayer(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		players := make([]game.Player, 0, 3)
+		for id := 0; id < 3; id++ {
+			players = append(players, &testPlayer{id: id})
+		}
+		rules := NewThreePlayer()
+		rules.ExchangeThree = i%2 == 0
+		g := game.New(players, game.WithRuleSet(rules))
+		if g.Deck().Len() != 72 {
+			t.Fatalf("两房应该有 72 张牌, 实际 %d 张", g.Deck().Len())
+		}
+		result, err := g.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+		winners := make(map[int]bool)
+		for _, w := range result.Wins {
+			winners[w.Winner.ID()] = true
+			if hasSuit(w.Winner.Tiles(), tile.WAN) {
+				t.Errorf("两房不应该有万子: %v", w.Winner.Tiles())
+			}
+		}
+		if !result.Exhausted && len(winners) != 2 {
+			t.Errorf("三人时没有摸完应该两家胡牌才结束: %d", len(winners))
+		}
+		total := 0
+		for _, s := range result.Scores {
+			total += s
+		}
+		if total != 0 {
+			t.Errorf("输赢分加起来应该为 0: %v", result.Scores)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package taiwan
+
+import (
+	"errors"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+	"github.com/mikodream/mahjong/game"
+	"github.com/mikodream/mahjong/win"
+)
+
+// ErrNotWin 不是和牌牌型
+var ErrNotWin = errors.New("taiwan: not a winning hand")
+
+// winOptions 台湾麻将只有五组加一对的标准牌型
+var winOptions = win.Options{}
+
+// Hand 胡牌时的信息
+type Hand struct {
+	Tiles     []card.ID        // 手牌，包含胡的那张牌，不包含明牌
+	Melds     []*game.ShowCard // 吃、碰、杠的牌，包括暗杠
+	WinTile   card.ID          // 胡的那张牌
+	SelfDrawn bool             // 自摸
+	Dealer    bool             // 庄家
+	SeatWind  card.ID          // 门风
+	RoundWind card.ID          // 圈风
+	Flowers   []card.ID        // 花牌
+	KongDraw  bool             // 杠后补牌自摸
+	LastDraw  bool             // 胡的是牌墙的最后一张牌，或者最后一张牌打出的牌
+	RobKong   bool             // 抢杠
+	FirstDraw bool             // 第一巡没有人鸣牌时自摸，庄家是天胡，闲家是地胡
+}
+
+// Result 算台结果，庄家和连庄的台数在结算时按付钱的玩家另算
+type Result struct {
+	Tais  []Tai
+	Total int
+}
+
+// Count 算台：枚举手牌的拆法和胡的那张牌的位置，取台数最高的
+func Count(h Hand) (Result, error) {
+	meldTiles := make([]card.ID, 0, len(h.Melds)*4)
+	for _, m := range h.Melds {
+		meldTiles = append(meldTiles, m.GetTiles()...)
+	}
+	if !card.IDInSlice(h.WinTile, h.Tiles) || !win.CanWinWith(h.Tiles, meldTiles, winOptions) {
+		return Result{}, ErrNotWin
+	}
+	c := newCounter(h, meldTiles)
+	melds := make([]win.Meld, 0, len(h.Melds))
+	for _, m := range h.Melds {
+		melds = append(melds, win.NewMeld(m))
+	}
+	best, found := Result{}, false
+	for _, d := range win.Decompose(h.Tiles, melds, h.WinTile, winOptions) {
+		r := newResult(c.tais(d))
+		if !found || r.Total > best.Total {
+			best, found = r, true
+		}
+	}
+	if !found {
+		return Result{}, ErrNotWin
+	}
+	return best, nil
+}
+
+func newResult(tais []Tai) Result {
+	r := Result{Tais: tais}
+	for _, t := range tais {
+		r.Total += t.Points()
+	}
+	return r
+}
+
+// tileCounts 按 card.ID 下标的张数
+type tileCounts [card.MAHJONG_WHITE + 1]int
+
+// counter 一手牌的算台过程中共用的数据
+type counter struct {
+	Hand
+	hand    tileCounts // 手牌
+	all     tileCounts // 手牌和明牌，杠算三张
+	exposed int        // 吃、碰、明杠的组数
+	waits   []card.ID
+}
+
+func newCounter(h Hand, meldTiles []card.ID) *counter {
+	c := &counter{Hand: h}
+	for _, t := range h.Tiles {
+		c.hand[t]++
+	}
+	c.all = c.hand
+	for _, m := range h.Melds {
+		tiles := m.GetTiles()
+		if len(tiles) == 4 {
+			tiles = tiles[:3]
+		}
+		if m.IsShow() && m.GetOpCode() != consts.AN_GANG {
+			c.exposed++
+		}
+		for _, t := range tiles {
+			c.all[t]++
+		}
+	}
+	rest := make([]card.ID, 0, len(h.Tiles)-1)
+	removed := false
+	for _, t := range h.Tiles {
+		if t == h.WinTile && !removed {
+			removed = true
+			continue
+		}
+		rest = append(rest, t)
+	}
+	c.waits = win.GetTingTilesWith(rest, meldTiles, winOptions)
+	return c
+}
+
+// tais 一种拆法的台数
+func (c *counter) tais(d win.Decomposition) []Tai {
+	switch {
+	case c.FirstDraw && c.SelfDrawn && len(c.Melds) == 0 && c.Dealer:
+		return append([]Tai{HeavenlyHand}, c.flowerTais()...)
+	case c.FirstDraw && c.SelfDrawn && len(c.Melds) == 0:
+		return append([]Tai{EarthlyHand}, c.flowerTais()...)
+	}
+
+	var tais []Tai
+	switch {
+	case c.exposed == 0 && c.SelfDrawn:
+		tais = append(tais, ConcealedSelfDrawn)
+	case c.exposed == 0:
+		tais = append(tais, Concealed)
+	case c.SelfDrawn:
+		tais = append(tais, SelfDrawn)
+	case c.exposed == len(c.Melds) && len(c.Tiles) == 2:
+		tais = append(tais, AllMelded)
+	}
+	tais = append(tais, c.flowerTais()...)
+
+	single := len(c.waits) == 1
+	if single {
+		tais = append(tais, SingleWait)
+	}
+	chows, pungs, concealed := 0, 0, 0
+	for i, m := range d.Melds {
+		switch m.Kind {
+		case win.Chow:
+			chows++
+		case win.Pung, win.Kong:
+			pungs++
+			// 胡别人打出的牌组成的刻子不算暗刻
+			if m.Concealed && (c.SelfDrawn || i != d.WinMeld) {
+				concealed++
+			}
+		}
+	}
+	if pungs == 0 && !c.SelfDrawn && !single &&
+		len(c.Flowers) == 0 && !c.hasHonors() {
+		tais = append(tais, AllChows)
+	}
+	switch {
+	case c.KongDraw && c.SelfDrawn:
+		tais = append(tais, KongDraw)
+	case c.LastDraw && c.SelfDrawn:
+		tais = append(tais, LastDraw)
+	case c.LastDraw:
+		tais = append(tais, LastDiscard)
+	}
+	if c.RobKong && !c.SelfDrawn {
+		tais = append(tais, RobKong)
+	}
+
+	switch {
+	case concealed >= 5:
+		tais = append(tais, FiveConcealed)
+	case concealed == 4:
+		tais = append(tais, FourConcealed)
+	case concealed == 3:
+		tais = append(tais, ThreeConcealed)
+	}
+	if chows == 0 {
+		tais = append(tais, AllPungs)
+	}
+	tais = append(tais, c.honorTais()...)
+	return append(tais, c.suitTais()...)
+}
+
+// flowerTais 正花、花杠，门风东对应春和梅
+func (c *counter) flowerTais() []Tai {
+	var tais []Tai
+	seat := seatIndex(c.SeatWind)
+	seasons, flowers := 0, 0
+	for _, f := range c.Flowers {
+		switch {
+		case f >= card.MAHJONG_SEASON1 && f <= card.MAHJONG_SEASON4:
+			seasons++
+			if int(f-card.MAHJONG_SEASON1) == seat {
+				tais = append(tais, SeatFlower)
+			}
+		case f >= card.MAHJONG_FLOWER1 && f <= card.MAHJONG_FLOWER4:
+			flowers++
+			if int(f-card.MAHJONG_FLOWER1) == seat {
+				tais = append(tais, SeatFlower)
+			}
+		}
+	}
+	if seasons == 4 {
+		tais = append(tais, FlowerKong)
+	}
+	if flowers == 4 {
+		tais = append(tais, FlowerKong)
+	}
+	return tais
+}
+
+// honorTais 三元牌、风牌，字牌只能组成刻子或者将，所以直接看张数
+func (c *counter) honorTais() []Tai {
+	var tais []Tai
+	dragons, dragonPair := 0, false
+	for _, t := range []card.ID{card.MAHJONG_GREE, card.MAHJONG_RED, card.MAHJONG_WHITE} {
+		switch {
+		case c.all[t] >= 3:
+			dragons++
+		case c.all[t] == 2:
+			dragonPair = true
+		}
+	}
+	switch {
+	case dragons == 3:
+		tais = append(tais, BigDragons)
+	case dragons == 2 && dragonPair:
+		tais = append(tais, SmallDragons)
+	default:
+		for i := 0; i < dragons; i++ {
+			tais = append(tais, DragonPung)
+		}
+	}
+
+	winds, windPair := 0, false
+	for t := card.MAHJONG_EAST; t <= card.MAHJONG_WEST; t++ {
+		switch {
+		case c.all[t] >= 3:
+			winds++
+		case c.all[t] == 2:
+			windPair = true
+		}
+	}
+	switch {
+	case winds == 4:
+		tais = append(tais, BigWinds)
+	case winds == 3 && windPair:
+		tais = append(tais, SmallWinds)
+	default:
+		if c.all[c.SeatWind] >= 3 {
+			tais = append(tais, SeatWindPung)
+		}
+		if c.all[c.RoundWind] >= 3 {
+			tais = append(tais, RoundWindPung)
+		}
+	}
+	return tais
+}
+
+// suitTais 混一色、清一色、字一色
+func (c *counter) suitTais() []Tai {
+	suits := make(map[card.ID]bool)
+	for t, n := range c.all {
+		if n > 0 && card.ID(t).IsSuit() {
+			suits[card.ID(t)/10] = true
+		}
+	}
+	switch {
+	case len(suits) == 0:
+		return []Tai{AllHonors}
+	case len(suits) == 1 && c.hasHonors():
+		return []Tai{HalfFlush}
+	case len(suits) == 1:
+		return []Tai{FullFlush}
+	}
+	return nil
+}
+
+func (c *counter) hasHonors() bool {
+	for t := card.MAHJONG_EAST; t <= card.MAHJONG_WHITE; t++ {
+		if c.all[t] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// seatIndex 门风对应的花牌序号：东 0、南 1、西 2、北 3
+func seatIndex(wind card.ID) int {
+	switch wind {
+	case card.MAHJONG_SOUTH:
+		return 1
+	case card.MAHJONG_WEST:
+		return 2
+	case card.MAHJONG_NORTH:
+		return 3
+	}
+	return 0
+}
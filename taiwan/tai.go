@@ -0,0 +1,69 @@
+package taiwan
+
+// Tai 台湾麻将的台数项目
+type Tai int
+
+const (
+	SelfDrawn          Tai = iota // 自摸
+	Concealed                     // 门清
+	ConcealedSelfDrawn            // 门清自摸（不求人）
+	AllMelded                     // 全求人
+	AllChows                      // 平胡
+	SeatFlower                    // 正花，每张 1 台
+	FlowerKong                    // 花杠：春夏秋冬或者梅兰竹菊齐了
+	DragonPung                    // 三元牌，每组 1 台
+	SeatWindPung                  // 门风
+	RoundWindPung                 // 圈风
+	SingleWait                    // 独听：边张、中洞、单吊
+	LastDraw                      // 海底捞月
+	LastDiscard                   // 河底捞鱼
+	KongDraw                      // 杠上开花
+	RobKong                       // 抢杠
+	ThreeConcealed                // 三暗刻
+	AllPungs                      // 碰碰胡
+	HalfFlush                     // 混一色
+	SmallDragons                  // 小三元
+	FourConcealed                 // 四暗刻
+	FiveConcealed                 // 五暗刻
+	FullFlush                     // 清一色
+	BigDragons                    // 大三元
+	SmallWinds                    // 小四喜
+	EightFlowers                  // 八仙过海
+	SevenRobOne                   // 七抢一
+	BigWinds                      // 大四喜
+	AllHonors                     // 字一色
+	EarthlyHand                   // 地胡
+	HeavenlyHand                  // 天胡
+
+	taiCount
+)
+
+var taiNames = [taiCount]string{
+	"自摸", "门清", "门清自摸", "全求人", "平胡", "正花", "花杠", "三元牌", "门风", "圈风",
+	"独听", "海底捞月", "河底捞鱼", "杠上开花", "抢杠", "三暗刻", "碰碰胡", "混一色", "小三元",
+	"四暗刻", "五暗刻", "清一色", "大三元", "小四喜", "八仙过海", "七抢一", "大四喜", "字一色", "地胡", "天胡",
+}
+
+var taiPoints = [taiCount]int{
+	SelfDrawn: 1, Concealed: 1, ConcealedSelfDrawn: 3, AllMelded: 2, AllChows: 2,
+	SeatFlower: 1, FlowerKong: 2, DragonPung: 1, SeatWindPung: 1, RoundWindPung: 1,
+	SingleWait: 1, LastDraw: 1, LastDiscard: 1, KongDraw: 1, RobKong: 1,
+	ThreeConcealed: 2, AllPungs: 4, HalfFlush: 4, SmallDragons: 4,
+	FourConcealed: 5, FiveConcealed: 8, FullFlush: 8, BigDragons: 8, SmallWinds: 8,
+	EightFlowers: 8, SevenRobOne: 8, BigWinds: 16, AllHonors: 16, EarthlyHand: 16, HeavenlyHand: 24,
+}
+
+func (t Tai) String() string {
+	if t < 0 || t >= taiCount {
+		return "未知台数"
+	}
+	return taiNames[t]
+}
+
+// Points 台数
+func (t Tai) Points() int {
+	if t < 0 || t >= taiCount {
+		return 0
+	}
+	return taiPoints[t]
+}
@@ -0,0 +1,148 @@
+// Package taiwan 台湾十六张麻将
+package taiwan
+
+import (
+	"context"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/game"
+	"github.com/mikodream/mahjong/win"
+)
+
+const (
+	handSize      = 16
+	defaultBase   = 100 // 默认底
+	defaultPerTai = 20  // 默认每台
+)
+
+// Rules 台湾十六张麻将
+// 144 张牌（带花牌），起手 16 张，胡牌是五组加一对共 17 张，不算七对和十三幺，没有起胡台数
+// 结算时每个付钱的玩家付 Base+台数×PerTai：胡别人打出的牌只有放枪的玩家付，自摸时三家都付
+// 赢家或者付钱的玩家是庄家时再加庄家 1 台和连庄、拉庄各 Streak 台
+// 每局牌的庄家和门风记录在 Rules 中，所以每个 Game 要用一个新的 Rules
+type Rules struct {
+	game.BaseRules
+	Base      int     // 底
+	PerTai    int     // 每台
+	RoundWind card.ID // 圈风，默认东
+	Streak    int     // 庄家连庄的次数
+
+	dealer *game.PlayerController
+	winds  map[int]card.ID
+	kongs  map[int]int // 玩家 ID => 上次出牌时杠的组数，用于判断杠上开花
+}
+
+// New 创建台湾麻将规则，底 100，每台 20
+func New() *Rules {
+	return &Rules{
+		BaseRules: game.BaseRules{Flowers: true},
+		Base:      defaultBase,
+		PerTai:    defaultPerTai,
+		RoundWind: card.MAHJONG_EAST,
+	}
+}
+
+func (r *Rules) HandSize() int {
+	return handSize
+}
+
+func (r *Rules) WinOptions() win.Options {
+	return winOptions
+}
+
+// MatchRules 一场比赛每局用的规则：按 base 的设置创建，圈风和连庄次数由 game.Match 决定
+func MatchRules(base *Rules) game.RulesFactory {
+	return func(hand game.HandInfo, prev game.RuleSet) game.RuleSet {
+		return &Rules{
+			BaseRules: base.BaseRules,
+			Base:      base.Base,
+			PerTai:    base.PerTai,
+			RoundWind: hand.RoundWind,
+			Streak:    hand.Repeat,
+		}
+	}
+}
+
+// AfterDeal 确定庄家和门风
+func (r *Rules) AfterDeal(ctx context.Context, g *game.Game) error {
+	if r.RoundWind == 0 {
+		r.RoundWind = card.MAHJONG_EAST
+	}
+	r.dealer = g.Players().Peek()
+	r.winds = make(map[int]card.ID)
+	r.kongs = make(map[int]int)
+	winds := []card.ID{card.MAHJONG_EAST, card.MAHJONG_SOUTH, card.MAHJONG_WEST, card.MAHJONG_NORTH}
+	for i := 0; i < g.Players().Len() && i < len(winds); i++ {
+		r.winds[g.Players().After(r.dealer.ID(), i).ID()] = winds[i]
+	}
+	return nil
+}
+
+// AfterDiscard 记录出牌时杠的组数
+func (r *Rules) AfterDiscard(g *game.Game, player *game.PlayerController, tile card.ID) error {
+	r.kongs[player.ID()] = countKongs(player)
+	return nil
+}
+
+// Count 计算一次胡牌的台数，自摸八张花是八仙过海，抢别人的第八张花是七抢一
+func (r *Rules) Count(g *game.Game, w game.Win) (Result, error) {
+	if w.Flower {
+		if w.SelfDrawn {
+			return newResult([]Tai{EightFlowers}), nil
+		}
+		return newResult([]Tai{SevenRobOne}), nil
+	}
+	player := w.Winner
+	melds := 0
+	g.Players().ForEach(func(p *game.PlayerController) {
+		melds += len(p.GetShowCard())
+	})
+	return Count(Hand{
+		Tiles:     player.Hand(),
+		Melds:     player.GetShowCard(),
+		WinTile:   w.Tile,
+		SelfDrawn: w.SelfDrawn,
+		Dealer:    player == r.dealer,
+		SeatWind:  r.winds[player.ID()],
+		RoundWind: r.RoundWind,
+		Flowers:   player.Flowers(),
+		KongDraw:  w.SelfDrawn && countKongs(player) > r.kongs[player.ID()],
+		LastDraw:  g.Deck().NoTiles(),
+		RobKong:   w.RobKong,
+		FirstDraw: w.SelfDrawn && len(g.Pile().Discards(player)) == 0 && melds == 0,
+	})
+}
+
+// Score 结算，见 Rules 的说明
+func (r *Rules) Score(g *game.Game, result *game.HandResult) (map[int]int, error) {
+	scores := make(map[int]int)
+	for _, w := range result.Wins {
+		res, err := r.Count(g, w)
+		if err != nil {
+			return nil, err
+		}
+		g.Players().ForEach(func(p *game.PlayerController) {
+			if p == w.Winner || !w.SelfDrawn && p != w.Discarder {
+				return
+			}
+			tai := res.Total
+			if p == r.dealer || w.Winner == r.dealer {
+				tai += 1 + 2*r.Streak
+			}
+			points := r.Base + tai*r.PerTai
+			scores[p.ID()] -= points
+			scores[w.Winner.ID()] += points
+		})
+	}
+	return scores, nil
+}
+
+func countKongs(player *game.PlayerController) int {
+	n := 0
+	for _, sc := range player.GetShowCard() {
+		if sc.GetTilesLen() == 4 {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,167 @@
+package taiwan
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+	"github.com/mikodream/mahjong/game"
+	"github.com/mikodream/mahjong/ting"
+)
+
+// testPlayer 能胡就胡，能碰就碰，能吃就吃，优先打出能听牌的牌
+type testPlayer struct {
+	id int
+}
+
+func (p *testPlayer) PlayerID() int {
+	return p.id
+}
+
+func (p *testPlayer) NickName() string {
+	return string(rune('A' + p.id))
+}
+
+func (p *testPlayer) Exchange(tiles []card.ID, gameState game.State) ([]card.ID, error) {
+	return tiles[:3], nil
+}
+
+func (p *testPlayer) Play(tiles []card.ID, gameState game.State) (card.ID, error) {
+	for discard := range ting.GetTingMapWith(tiles, nil, winOptions) {
+		return discard, nil
+	}
+	best, bestScore := tiles[0], 100
+	for _, t := range tiles {
+		score := 0
+		for _, o := range tiles {
+			if d := int(o) - int(t); d >= -2 && d <= 2 {
+				score++
+			}
+		}
+		if score < bestScore {
+			best, bestScore = t, score
+		}
+	}
+	return best, nil
+}
+
+func (p *testPlayer) Take(tiles []card.ID, gameState game.State) (int, []card.ID, error) {
+	top := gameState.LastPlayedTile
+	for _, c := range gameState.CanWin {
+		if c.ID() == p.id {
+			return consts.WIN, []card.ID{top}, nil
+		}
+	}
+	for _, op := range gameState.SpecialPrivileges[p.id] {
+		if op == consts.PENG {
+			return consts.PENG, []card.ID{top, top, top}, nil
+		}
+		if op == consts.CHI {
+			chi := card.CanChiTiles(tiles[:len(tiles)-1], top)[0]
+			return consts.CHI, []card.ID{chi[0], chi[1], top}, nil
+		}
+	}
+	return 0, nil, nil
+}
+
+func (p *testPlayer) Act(tiles []card.ID, gameState game.State) (int, card.ID, error) {
+	for _, op := range gameState.SpecialPrivileges[p.id] {
+		if op == consts.WIN {
+			return consts.WIN, 0, nil
+		}
+	}
+	return 0, 0, nil
+}
+
+func TestCount(t *testing.T) {
+	cases := []struct {
+		name  string
+		hand  Hand
+		tais  []Tai
+		total int
+	}{
+		{
+			name:  "门清平胡",
+			hand:  Hand{Tiles: []card.ID{2, 3, 4, 12, 13, 14, 15, 16, 17, 22, 23, 24, 26, 27, 28, 9, 9}, WinTile: 4},
+			tais:  []Tai{Concealed, AllChows},
+			total: 3,
+		},
+		{
+			name: "自摸，正花，三元牌",
+			hand: Hand{
+				Tiles: []card.ID{4, 5, 6, 14, 15, 16, 24, 25, 26, 29, 29},
+				Melds: []*game.ShowCard{
+					game.NewShowCard(consts.PENG, 1, []card.ID{41, 41, 41}, true, false),
+					game.NewShowCard(consts.CHI, 3, []card.ID{1, 2, 3}, true, false),
+				},
+				WinTile: 6, SelfDrawn: true, SeatWind: card.MAHJONG_SOUTH, Flowers: []card.ID{52, 63},
+			},
+			tais:  []Tai{SelfDrawn, SeatFlower, DragonPung},
+			total: 3,
+		},
+		{
+			name: "放枪的刻子不算暗刻",
+			hand: Hand{
+				Tiles:   []card.ID{1, 1, 1, 5, 5, 5, 12, 12, 12, 27, 27, 27, 33, 33, 33, 9, 9},
+				WinTile: 27, SeatWind: card.MAHJONG_EAST, RoundWind: card.MAHJONG_EAST,
+			},
+			tais:  []Tai{Concealed, FourConcealed, AllPungs},
+			total: 10,
+		},
+		{
+			name:  "天胡",
+			hand:  Hand{Tiles: []card.ID{2, 3, 4, 12, 13, 14, 15, 16, 17, 22, 23, 24, 26, 27, 28, 9, 9}, WinTile: 9, SelfDrawn: true, Dealer: true, FirstDraw: true},
+			tais:  []Tai{HeavenlyHand},
+			total: 24,
+		},
+	}
+	for _, c := range cases {
+		r, err := Count(c.hand)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(r.Tais, c.tais) || r.Total != c.total {
+			t.Errorf("%s: 期望 %v %d 台, 实际 %v %d 台", c.name, c.tais, c.total, r.Tais, r.Total)
+		}
+	}
+
+	if _, err := Count(Hand{Tiles: []card.ID{1, 1, 3, 3, 5, 5, 7, 7, 11, 11, 13, 13, 15, 15}, WinTile: 15}); err != ErrNotWin {
+		t.Errorf("七对不算和牌，应该返回 ErrNotWin, 实际 %v", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	for i := 0; i < 30; i++ {
+		players := make([]game.Player, 0, 4)
+		for id := 0; id < 4; id++ {
+			players = append(players, &testPlayer{id: id})
+		}
+		rules := New()
+		g := game.New(players, game.WithRuleSet(rules))
+		result, err := g.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+		total := 0
+		for _, s := range result.Scores {
+			total += s
+		}
+		if total != 0 {
+			t.Errorf("输赢分加起来应该为 0: %v", result.Scores)
+		}
+		for _, w := range result.Wins {
+			if w.Flower {
+				continue
+			}
+			if n := len(w.Winner.Hand()) + 3*len(w.Winner.GetShowCard()); n != 17 {
+				t.Errorf("%s 胡牌应该是 17 张, 实际 %d 张: %v", w.Winner.Name(), n, w.Winner.Hand())
+			}
+			if _, err := rules.Count(g, w); err != nil {
+				t.Errorf("%s 不是和牌牌型: %v %v", w.Winner.Name(), w.Winner.Hand(), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package ting
+
+import (
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/win"
+)
+
+// Shanten 向听数：至少还要换几张牌才能听牌，0 是听牌，-1 是已经胡牌
+// 按默认规则计算标准胡牌、七对和十三幺，取最小的一个
+// handCards 是手牌（打出一张之前的 3n+2 张或者打出之后的 3n+1 张），showCards 是碰杠吃的牌
+func Shanten(handCards, showCards []card.ID) int {
+	return ShantenWith(handCards, showCards, win.DefaultOptions)
+}
+
+// ShantenWith 按指定的规则计算向听数，有碰杠吃的牌时不算七对和十三幺
+// 赖子按每张少一向听估算
+func ShantenWith(handCards, showCards []card.ID, opts win.Options) int {
+	tiles := make([]card.ID, 0, len(handCards))
+	for _, t := range handCards {
+		if !t.IsBonus() {
+			tiles = append(tiles, t)
+		}
+	}
+	normal, wildcards := opts.Wildcards.Split(tiles)
+	lowest := 0
+	if len(tiles)%3 == 2 {
+		lowest = -1
+	}
+	best := standardShanten(normal, len(tiles)/3)
+	if len(showCards) == 0 && len(tiles) >= 13 {
+		if n := SevenPairsShanten(normal); opts.SevenPairs && n < best {
+			best = n
+		}
+		if n := ThirteenOrphansShanten(normal); opts.ThirteenOrphans && n < best {
+			best = n
+		}
+	}
+	best -= wildcards
+	if best < lowest {
+		best = lowest
+	}
+	return best
+}
+
+// StandardShanten 标准胡牌（n 组加一对）的向听数，n 由手牌张数决定
+func StandardShanten(handCards []card.ID) int {
+	return standardShanten(handCards, len(handCards)/3)
+}
+
+// SevenPairsShanten 七对的向听数，和 win 一样四张一样的牌算两对
+func SevenPairsShanten(handCards []card.ID) int {
+	counts := card.NewCounts(handCards)
+	pairs := 0
+	for _, n := range counts {
+		pairs += int(n) / 2
+	}
+	return 6 - pairs
+}
+
+// ThirteenOrphansShanten 十三幺的向听数
+func ThirteenOrphansShanten(handCards []card.ID) int {
+	counts := card.NewCounts(handCards)
+	kinds, pair := 0, 0
+	counts.Each(func(id card.ID, n int) bool {
+		if isOrphan(id) {
+			kinds++
+			if n >= 2 {
+				pair = 1
+			}
+		}
+		return true
+	})
+	return 13 - kinds - pair
+}
+
+func isOrphan(t card.ID) bool {
+	return t.IsHonor() && !t.IsBonus() || t.IsSuit() && (t.Rank() == 1 || t.Rank() == 9)
+}
+
+// standardShanten 用 sets 组加一对胡牌的向听数
+// 向听数 = 2×(还差的组数) - 搭子数 - 有没有雀头，组数加搭子数不超过 sets
+func standardShanten(handCards []card.ID, sets int) int {
+	s := &shantenSearch{counts: card.NewCounts(handCards), sets: sets, best: 2 * sets}
+	s.search(0)
+	return s.best
+}
+
+type shantenSearch struct {
+	counts card.Counts // 花牌不参与计算
+	sets   int         // 手牌需要组成的组数
+	melds  int         // 已经组成的组（刻子、顺子）
+	taatsu int         // 搭子：对子、两面、嵌张
+	pair   int         // 有没有雀头
+	best   int
+}
+
+// search 从 i 开始依次拆出组、雀头和搭子，剩下的牌当作孤张
+func (s *shantenSearch) search(i int) {
+	for i < len(s.counts) && s.counts[i] == 0 {
+		i++
+	}
+	if i == len(s.counts) {
+		s.evaluate()
+		return
+	}
+	c := &s.counts
+	suited := card.IsSuitIndex(i)
+	rank := card.IndexRank(i)
+	if c[i] >= 3 {
+		c[i] -= 3
+		s.melds++
+		s.search(i)
+		s.melds--
+		c[i] += 3
+	}
+	if suited && rank <= 7 && c[i+1] > 0 && c[i+2] > 0 {
+		c[i]--
+		c[i+1]--
+		c[i+2]--
+		s.melds++
+		s.search(i)
+		s.melds--
+		c[i]++
+		c[i+1]++
+		c[i+2]++
+	}
+	if c[i] >= 2 {
+		c[i] -= 2
+		if s.pair == 0 {
+			s.pair = 1
+			s.search(i)
+			s.pair = 0
+		}
+		s.taatsu++
+		s.search(i)
+		s.taatsu--
+		c[i] += 2
+	}
+	if suited && rank <= 8 && c[i+1] > 0 {
+		c[i]--
+		c[i+1]--
+		s.taatsu++
+		s.search(i)
+		s.taatsu--
+		c[i]++
+		c[i+1]++
+	}
+	if suited && rank <= 7 && c[i+2] > 0 {
+		c[i]--
+		c[i+2]--
+		s.taatsu++
+		s.search(i)
+		s.taatsu--
+		c[i]++
+		c[i+2]++
+	}
+	c[i]--
+	s.search(i)
+	c[i]++
+}
+
+func (s *shantenSearch) evaluate() {
+	taatsu := s.taatsu
+	if s.melds+taatsu > s.sets {
+		taatsu = s.sets - s.melds
+	}
+	if shanten := 2*(s.sets-s.melds) - taatsu - s.pair; shanten < s.best {
+		s.best = shanten
+	}
+}
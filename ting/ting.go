@@ -8,20 +8,32 @@ import (
 // CanTing 判断牌型是否可以听牌
 // 返回是否可听、听什么
 func CanTing(handCards, showCards []card.ID) (bool, []card.ID) {
-	var canTing = false
-	tingCards := make([]card.ID, 0)
+	return CanTingWith(handCards, showCards, win.DefaultOptions)
+}
 
-	// 循环将可能听的牌，带入到手牌，再用胡牌算法检测是否可胡
-	for _, t := range GetMaybeTing(handCards, showCards) {
-		tempHand := make([]card.ID, len(handCards), len(handCards)+1)
-		copy(tempHand, handCards)
-		tempHand = append(tempHand, t)
-		if win.CanWin(tempHand, showCards) {
-			canTing = true
+// CanTingWith 按指定的胡牌规则判断牌型是否可以听牌
+func CanTingWith(handCards, showCards []card.ID, opts win.Options) (bool, []card.ID) {
+	counts := card.NewCounts(handCards)
+	if counts.Len() != len(handCards) {
+		// 手里有花牌，胡不了
+		return false, []card.ID{}
+	}
+	tingCards := tingCounts(&counts, opts)
+	return len(tingCards) > 0, tingCards
+}
+
+// tingCounts 按张数逐个试摸到每一种牌，用 win.CanWinCounts 判断，不用每次复制手牌
+func tingCounts(counts *card.Counts, opts win.Options) []card.ID {
+	tingCards := make([]card.ID, 0)
+	for i := 0; i < card.TileKinds; i++ {
+		t := card.FromIndex(i)
+		counts.Add(t)
+		if win.CanWinCounts(counts, opts) {
 			tingCards = append(tingCards, t)
 		}
+		counts.Remove(t)
 	}
-	return canTing, tingCards
+	return tingCards
 }
 
 // GetMaybeTing 获取哪些牌是可能听的
@@ -66,21 +78,39 @@ func GetMaybeTing(handCards, showCards []card.ID) []card.ID {
 // key: 打什么
 // value: 听哪些
 func GetTingMap(handCards, showCards []card.ID) map[card.ID][]card.ID {
-	tingMap := make(map[card.ID][]card.ID)
+	return GetTingMapWith(handCards, showCards, win.DefaultOptions)
+}
 
-	// 去重手牌
-	uniqueHands := make(map[card.ID]bool)
-	for _, c := range handCards {
-		uniqueHands[c] = true
+// GetTingMapWith 按指定的胡牌规则获取可听的列表
+func GetTingMapWith(handCards, showCards []card.ID, opts win.Options) map[card.ID][]card.ID {
+	tingMap := make(map[card.ID][]card.ID)
+	counts := card.NewCounts(handCards)
+	switch len(handCards) - counts.Len() {
+	case 0:
+	case 1:
+		// 手里有一张花牌，只有打出花牌才可能听
+		for _, t := range handCards {
+			if counts.Count(t) == 0 {
+				if tingCards := tingCounts(&counts, opts); len(tingCards) > 0 {
+					tingMap[t] = tingCards
+				}
+			}
+		}
+		return tingMap
+	default:
+		return tingMap
 	}
 
-	for playCard := range uniqueHands {
-		// 删除一张牌
-		tempHand := sliceDel(handCards, playCard)
-
-		if ting, tingCards := CanTing(tempHand, showCards); ting {
+	// 每种牌打出一张，剩下的牌能听什么
+	for i := 0; i < card.TileKinds; i++ {
+		playCard := card.FromIndex(i)
+		if !counts.Remove(playCard) {
+			continue
+		}
+		if tingCards := tingCounts(&counts, opts); len(tingCards) > 0 {
 			tingMap[playCard] = tingCards
 		}
+		counts.Add(playCard)
 	}
 	return tingMap
 }
@@ -1,11 +1,13 @@
 package ting
 
 import (
+	"math/rand"
 	"reflect"
 	"sort"
 	"testing"
 
 	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/win"
 )
 
 // 测试可能听的牌
@@ -106,3 +108,189 @@ func TestCanTing(t *testing.T) {
 		t.Error("验证叫牌失败5")
 	}
 }
+
+func TestGetTingMap(t *testing.T) {
+	tingMap := GetTingMap([]card.ID{1, 1, 1, 2, 3, 4, 5, 6, 7, 8, 9, 9, 9, 21}, nil)
+	if len(tingMap[21]) != 9 || len(tingMap[1]) != 0 {
+		t.Errorf("九莲宝灯打 1 饼听九种牌, got %v", tingMap)
+	}
+	// 手里有花牌时只有打花牌才能听
+	tingMap = GetTingMap([]card.ID{1, 1, 1, 2, 3, 4, 5, 6, 7, 8, 9, 9, 9, card.MAHJONG_SEASON1}, nil)
+	if len(tingMap) != 1 || len(tingMap[card.MAHJONG_SEASON1]) != 9 {
+		t.Errorf("应该打出花牌听牌, got %v", tingMap)
+	}
+}
+
+func TestCanTingWithWildcards(t *testing.T) {
+	opts := win.DefaultOptions
+	opts.Wildcards = card.Wildcards{card.MAHJONG_RED}
+
+	// 手里有赖子时，远处的牌也可能胡
+	isTing, tingCards := CanTingWith([]card.ID{1, 2, 31, 42}, nil, opts)
+	if !isTing || !card.IDInSlice(31, tingCards) || !card.IDInSlice(3, tingCards) || !card.IDInSlice(42, tingCards) {
+		t.Errorf("验证赖子叫牌失败1, got %v", tingCards)
+	}
+
+	// 手里没有赖子时，摸到赖子也能胡
+	isTing, tingCards = CanTingWith([]card.ID{1, 2, 3, 31}, nil, opts)
+	sort.Slice(tingCards, func(i, j int) bool { return tingCards[i] < tingCards[j] })
+	if !isTing || !reflect.DeepEqual(tingCards, []card.ID{31, 42}) {
+		t.Errorf("验证赖子叫牌失败2, got %v", tingCards)
+	}
+}
+
+func TestShanten(t *testing.T) {
+	cases := []struct {
+		hand    []card.ID
+		show    []card.ID
+		shanten int
+	}{
+		{[]card.ID{1, 1, 1, 2, 3, 4, 5, 6, 7, 8, 9, 9, 9}, nil, 0},                         // 九莲宝灯
+		{[]card.ID{1, 1, 1, 2, 3, 4, 5, 6, 7, 8, 9, 9, 9, 5}, nil, -1},                     // 胡牌
+		{[]card.ID{1, 4, 7, 12, 15, 18, 23, 26, 29, 31, 32, 33, 41}, nil, 6},               // 七对六向听
+		{[]card.ID{1, 9, 11, 19, 21, 29, 31, 32, 33, 34, 41, 42, 1}, nil, 0},               // 十三幺听牌
+		{[]card.ID{1, 2, 4, 5, 7, 8, 11, 12, 14, 15, 21, 22, 31}, nil, 4},                  // 搭子太多
+		{[]card.ID{1, 1, 3, 3, 5, 5, 7, 7, 9, 9, 11, 11, 18}, nil, 0},                      // 七对听牌
+		{[]card.ID{1, 1, 3, 3, 5, 5, 7, 7, 9, 9, 11, 11, 18}, []card.ID{8, 8, 8}, 3},       // 有碰的牌不算七对
+		{[]card.ID{2, 3, 4, 12, 13, 14, 22, 23, 24, 31, 31, 5, 6, 7, 42, 42, 42}, nil, -1}, // 十七张胡牌
+		{[]card.ID{2, 3, 4, 12, 13, 14, 22, 23, 24, 31, 31, 5, 6, 7, 42, 43}, nil, 1},      // 十六张一向听
+		{[]card.ID{2, 3, 22, 23}, []card.ID{5, 5, 5, 8, 8, 8, 8, 31, 31, 31}, 1},           // 副露
+		{[]card.ID{2, 3, 22, 23, 61, 62}, []card.ID{5, 5, 5, 8, 8, 8, 8, 31, 31, 31}, 1},   // 花牌不算
+		{[]card.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 11, 12, 13, 21}, nil, 0},                     // 单钓
+		{[]card.ID{1, 3, 5, 7, 9, 11, 13, 15, 17, 19, 21, 23, 25}, nil, 4},                 // 嵌张
+		{[]card.ID{1, 1, 1, 1, 2, 2, 2, 2, 3, 3, 3, 3, 4}, nil, 0},                         // 四张一样的牌
+		{[]card.ID{1, 1, 1, 1, 2, 2, 2, 2, 31, 31, 31, 31, 32}, []card.ID{}, 0},            // 七对四张算两对
+	}
+	for i, c := range cases {
+		if got := Shanten(c.hand, c.show); got != c.shanten {
+			t.Errorf("向听数计算错误%d: %v 应该是 %d, 实际 %d", i+1, c.hand, c.shanten, got)
+		}
+	}
+
+	opts := win.Options{}
+	if got := ShantenWith([]card.ID{1, 1, 3, 3, 5, 5, 7, 7, 9, 9, 11, 11, 18}, nil, opts); got != 3 {
+		t.Errorf("不能胡七对时应该按标准胡牌计算, 实际 %d", got)
+	}
+	opts.Wildcards = card.Wildcards{card.MAHJONG_RED}
+	if got := ShantenWith([]card.ID{1, 2, 3, 11, 12, 13, 21, 22, 23, 31, 33, 42, 42}, nil, opts); got != 0 {
+		t.Errorf("赖子计算向听数错误, 实际 %d", got)
+	}
+}
+
+// 向听数为 0 和 CanTing 的结果一致
+func TestShantenTing(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tiles := make([]card.ID, len(card.MahjongCards136))
+	copy(tiles, card.MahjongCards136)
+	for i := 0; i < 300; i++ {
+		rng.Shuffle(len(tiles), func(i, j int) { tiles[i], tiles[j] = tiles[j], tiles[i] })
+		hand := make([]card.ID, 13)
+		copy(hand, tiles)
+		// 一半的牌局用同一种花色，容易组成听牌
+		if i%2 == 1 {
+			for j := range hand {
+				hand[j] = card.ID(rng.Intn(9) + 1)
+			}
+		}
+		ting, _ := CanTing(hand, nil)
+		if shanten := Shanten(hand, nil); ting != (shanten == 0) {
+			t.Errorf("%v 向听数 %d, CanTing %v", hand, shanten, ting)
+		}
+	}
+}
+
+func TestUkeire(t *testing.T) {
+	// 两面搭子 4-5 万等 3 万和 6 万，其中 3 万见过两张
+	hand := []card.ID{1, 2, 3, 4, 5, 11, 12, 13, 21, 22, 23, 31, 31}
+	u := GetUkeire(hand, nil, []card.ID{3, 3})
+	if u.Shanten != 0 || !reflect.DeepEqual(u.Tiles, []card.ID{3, 6}) || u.Left[3] != 1 || u.Left[6] != 4 || u.Total != 5 {
+		t.Errorf("进张计算错误: %+v", u)
+	}
+
+	// 碰的牌和见过的牌都不算剩下的张数
+	u = GetUkeire([]card.ID{4, 5, 31, 31}, []card.ID{6, 6, 6, 12, 12, 12, 22, 22, 22}, []card.ID{3, 3, 3})
+	if u.Shanten != 0 || u.Left[3] != 1 || u.Left[6] != 1 || u.Total != 2 {
+		t.Errorf("进张剩下的张数错误: %+v", u)
+	}
+
+	// 摸到隔张也能减少向听数：1 饼摸 3 饼、7 饼摸 5 饼都是坎张
+	u = GetUkeire([]card.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 21, 27, 31, 33}, nil, nil)
+	if u.Shanten != 2 || !reflect.DeepEqual(u.Tiles, []card.ID{21, 22, 23, 25, 26, 27, 28, 29, 31, 33}) || u.Total != 36 {
+		t.Errorf("坎张进张计算错误: %+v", u)
+	}
+
+	// 打孤张红中进张最多，其次是打 9 万、打 1 万
+	hand = []card.ID{1, 3, 4, 5, 9, 11, 12, 13, 21, 22, 23, 31, 31, 42}
+	analysis := AnalyzeDiscards(hand, nil, nil)
+	if len(analysis) != 13 || analysis[0].Discard != 42 || analysis[1].Discard != 9 || analysis[2].Discard != 1 {
+		t.Fatalf("打牌分析错误: %+v", analysis)
+	}
+	for i := 1; i < len(analysis); i++ {
+		a, b := analysis[i-1], analysis[i]
+		if a.Shanten > b.Shanten || a.Shanten == b.Shanten && a.Total < b.Total {
+			t.Errorf("打牌分析没有按牌效排序: %+v %+v", a, b)
+		}
+	}
+	for _, u := range analysis {
+		for _, tile := range u.Tiles {
+			ok := ShantenWith(append(sliceDel(hand, u.Discard), tile), nil, win.DefaultOptions) < u.Shanten
+			if !ok {
+				t.Errorf("打 %d 摸 %d 不能减少向听数", u.Discard, tile)
+			}
+		}
+	}
+}
+
+func TestWaits(t *testing.T) {
+	cases := []struct {
+		hand  []card.ID
+		tile  card.ID
+		waits []Wait
+	}{
+		{[]card.ID{1, 2, 11, 12, 13, 21, 22, 23, 31, 31, 31, 5, 5}, 3, []Wait{EdgeWait}},
+		{[]card.ID{8, 9, 11, 12, 13, 21, 22, 23, 31, 31, 31, 5, 5}, 7, []Wait{EdgeWait}},
+		{[]card.ID{4, 6, 11, 12, 13, 21, 22, 23, 31, 31, 31, 8, 8}, 5, []Wait{ClosedWait}},
+		// 4556 听 5 可以是嵌张也可以是单钓
+		{[]card.ID{4, 6, 11, 12, 13, 21, 22, 23, 31, 31, 31, 5, 5}, 5, []Wait{ClosedWait, PairWait}},
+		{[]card.ID{4, 5, 11, 12, 13, 21, 22, 23, 31, 31, 31, 8, 8}, 3, []Wait{OpenWait}},
+		{[]card.ID{4, 4, 11, 12, 13, 21, 22, 23, 31, 31, 31, 8, 8}, 8, []Wait{DualPungWait}},
+		{[]card.ID{1, 2, 3, 11, 12, 13, 21, 22, 23, 31, 31, 31, 8}, 8, []Wait{PairWait}},
+		// 2345 听 2、5 都是单钓
+		{[]card.ID{2, 3, 4, 5, 11, 12, 13, 21, 22, 23, 31, 31, 31}, 2, []Wait{PairWait}},
+		// 3455 听 5 可以是单钓也可以是两面
+		{[]card.ID{3, 4, 5, 5, 11, 12, 13, 21, 22, 23, 31, 31, 31}, 5, []Wait{PairWait, OpenWait}},
+		// 1113 听 2 是嵌张，听 3 是单钓
+		{[]card.ID{1, 1, 1, 3, 11, 12, 13, 21, 22, 23, 31, 31, 31}, 2, []Wait{ClosedWait}},
+		{[]card.ID{1, 1, 1, 3, 11, 12, 13, 21, 22, 23, 31, 31, 31}, 3, []Wait{PairWait}},
+		// 九莲宝灯听九种牌
+		{[]card.ID{1, 1, 1, 2, 3, 4, 5, 6, 7, 8, 9, 9, 9}, 5, []Wait{PairWait, MultiWait}},
+		{[]card.ID{1, 1, 3, 3, 5, 5, 7, 7, 9, 9, 11, 11, 18}, 18, []Wait{PairWait}},
+		{[]card.ID{1, 9, 11, 19, 21, 29, 31, 32, 33, 34, 41, 42, 43}, 1, []Wait{MultiWait}},
+		{[]card.ID{1, 2, 11, 12, 13, 21, 22, 23, 31, 31, 31, 5, 5}, 4, nil},
+	}
+	for i, c := range cases {
+		if got := Waits(c.hand, nil, c.tile, win.DefaultOptions); !reflect.DeepEqual(got, c.waits) {
+			t.Errorf("听牌形状错误%d: 应该是 %v, 实际 %v", i+1, c.waits, got)
+		}
+	}
+
+	hand := []card.ID{3, 4, 5, 5, 11, 12, 13, 21, 22, 23, 31, 31, 31}
+	if w, ok := ClassifyWait(hand, nil, 5, win.DefaultOptions, HardWaitsFirst); !ok || w != PairWait {
+		t.Errorf("难胡的形状优先时应该是单钓, 实际 %v", w)
+	}
+	if w, ok := ClassifyWait(hand, nil, 5, win.DefaultOptions, OpenWaitsFirst); !ok || w != OpenWait {
+		t.Errorf("两面优先时应该是两面, 实际 %v", w)
+	}
+	if _, ok := ClassifyWait(hand, nil, 7, win.DefaultOptions, HardWaitsFirst); ok {
+		t.Errorf("不是胡牌时不应该有听牌形状")
+	}
+}
+
+func BenchmarkShanten(b *testing.B) {
+	hand := []card.ID{1, 2, 4, 5, 7, 8, 11, 12, 14, 15, 21, 22, 23, 31}
+	for i := 0; i < b.N; i++ {
+		for j := range hand {
+			Shanten(append(append([]card.ID{}, hand[:j]...), hand[j+1:]...), nil)
+		}
+	}
+}
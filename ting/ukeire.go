@@ -0,0 +1,123 @@
+package ting
+
+import (
+	"sort"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/win"
+)
+
+const tileCopies = 4 // 每种牌四张
+
+// Ukeire 进张：打出 Discard 之后摸到哪些牌能减少向听数，这些牌还剩几张没见过
+type Ukeire struct {
+	Discard card.ID         // 打出的牌，手牌是 3n+1 张没有打牌时是 0
+	Shanten int             // 打出之后的向听数
+	Tiles   []card.ID       // 进张，按 card.AllTiles 的顺序
+	Left    map[card.ID]int // 进张 => 还没见过的张数
+	Total   int             // 进张一共还剩几张
+}
+
+// GetUkeire 3n+1 张手牌的进张
+// visible 是除了自己的手牌和 showCards 以外见过的牌：牌池里的牌、其他玩家碰杠吃的牌
+func GetUkeire(handCards, showCards, visible []card.ID) Ukeire {
+	return GetUkeireWith(handCards, showCards, visible, win.DefaultOptions)
+}
+
+// GetUkeireWith 按指定的胡牌规则计算进张
+func GetUkeireWith(handCards, showCards, visible []card.ID, opts win.Options) Ukeire {
+	left := unseen(handCards, showCards, visible)
+	return ukeire(handCards, showCards, &left, opts)
+}
+
+// AnalyzeDiscards 3n+2 张手牌打出每一种牌之后的进张
+// 按向听数从小到大、进张张数从多到少排序，第一个就是牌效最好的打法
+func AnalyzeDiscards(handCards, showCards, visible []card.ID) []Ukeire {
+	return AnalyzeDiscardsWith(handCards, showCards, visible, win.DefaultOptions)
+}
+
+// AnalyzeDiscardsWith 按指定的胡牌规则分析每一种打法的进张
+func AnalyzeDiscardsWith(handCards, showCards, visible []card.ID, opts win.Options) []Ukeire {
+	// 打出去的牌也是见过的牌，所以张数按打牌之前的手牌算
+	left := unseen(handCards, showCards, visible)
+	checked := make(map[card.ID]bool)
+	var results []Ukeire
+	for _, discard := range handCards {
+		if checked[discard] || discard.IsBonus() {
+			continue
+		}
+		checked[discard] = true
+		u := ukeire(sliceDel(handCards, discard), showCards, &left, opts)
+		u.Discard = discard
+		results = append(results, u)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Shanten != b.Shanten {
+			return a.Shanten < b.Shanten
+		}
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.Discard < b.Discard
+	})
+	return results
+}
+
+// ukeire 逐个试摸到每一种可能有用的牌，向听数减少的就是进张
+func ukeire(handCards, showCards []card.ID, left *card.Counts, opts win.Options) Ukeire {
+	u := Ukeire{
+		Shanten: ShantenWith(handCards, showCards, opts),
+		Left:    make(map[card.ID]int),
+	}
+	tempHand := make([]card.ID, len(handCards), len(handCards)+1)
+	copy(tempHand, handCards)
+	for _, t := range candidates(handCards, showCards, opts) {
+		if ShantenWith(append(tempHand, t), showCards, opts) < u.Shanten {
+			u.Tiles = append(u.Tiles, t)
+			u.Left[t] = left.Count(t)
+			u.Total += left.Count(t)
+		}
+	}
+	return u
+}
+
+// candidates 摸到以后可能减少向听数的牌：手牌本身、上下张和隔张、赖子，能胡十三幺时再加上所有么九牌
+// 手里有赖子时任何牌都可能有用
+func candidates(handCards, showCards []card.ID, opts win.Options) []card.ID {
+	if _, jokers := opts.Wildcards.Split(handCards); jokers > 0 {
+		return card.AllTiles
+	}
+	handInts := make([]int, len(handCards))
+	for i, v := range handCards {
+		handInts[i] = int(v)
+	}
+	var maybe card.Counts
+	for _, t := range card.GetRelationTiles(handInts...) {
+		maybe.Add(card.ID(t))
+	}
+	for _, w := range opts.Wildcards {
+		maybe.Add(w)
+	}
+	var tiles []card.ID
+	for _, t := range card.AllTiles {
+		if maybe.Count(t) > 0 || opts.ThirteenOrphans && len(showCards) == 0 && isOrphan(t) {
+			tiles = append(tiles, t)
+		}
+	}
+	return tiles
+}
+
+// unseen 每种牌还有几张没见过
+func unseen(handCards, showCards, visible []card.ID) card.Counts {
+	var left card.Counts
+	for i := range left {
+		left[i] = tileCopies
+	}
+	for _, tiles := range [][]card.ID{handCards, showCards, visible} {
+		for _, t := range tiles {
+			left.Remove(t)
+		}
+	}
+	return left
+}
@@ -0,0 +1,108 @@
+package ting
+
+import (
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/win"
+)
+
+// Wait 听牌的形状
+type Wait int
+
+const (
+	EdgeWait     Wait = iota // 边张：12 听 3、89 听 7
+	ClosedWait               // 嵌张（坎张）：13 听 2
+	PairWait                 // 单钓：听将，七对和十三幺只听一张时也算
+	DualPungWait             // 对倒（双碰）：两个对子听其中一个成刻子
+	OpenWait                 // 两面：23 听 1、4
+	MultiWait                // 多面：听三种或者更多的牌
+)
+
+var waitNames = map[Wait]string{
+	EdgeWait:     "边张",
+	ClosedWait:   "嵌张",
+	PairWait:     "单钓",
+	DualPungWait: "对倒",
+	OpenWait:     "两面",
+	MultiWait:    "多面",
+}
+
+func (w Wait) String() string {
+	return waitNames[w]
+}
+
+// HardWaitsFirst 边张、嵌张、单钓优先，适合给难胡的听牌加番的玩法，比如国标麻将、台湾麻将
+var HardWaitsFirst = []Wait{EdgeWait, ClosedWait, PairWait, DualPungWait, OpenWait, MultiWait}
+
+// OpenWaitsFirst 多面、两面优先，适合两面听才能算番的玩法，比如日本麻将的平和
+var OpenWaitsFirst = []Wait{MultiWait, OpenWait, DualPungWait, PairWait, ClosedWait, EdgeWait}
+
+// Waits 胡 winTile 时所有可能的听牌形状，按 Wait 的顺序
+// handCards 是胡牌之前的手牌，不是胡牌时返回空
+// 同一手牌可能有几种拆法，每种拆法里胡的牌所在的组决定一种形状，听三种以上的牌时再加上多面
+// 手里有赖子时拆法不确定，只判断多面
+func Waits(handCards, showCards []card.ID, winTile card.ID, opts win.Options) []Wait {
+	canTing, tingCards := CanTingWith(handCards, showCards, opts)
+	if !canTing || !card.IDInSlice(winTile, tingCards) {
+		return nil
+	}
+	hand := make([]card.ID, len(handCards), len(handCards)+1)
+	copy(hand, handCards)
+	hand = append(hand, winTile)
+
+	found := make(map[Wait]bool)
+	if len(tingCards) >= 3 {
+		found[MultiWait] = true
+	}
+	var decompositions []win.Decomposition
+	if _, jokers := opts.Wildcards.Split(hand); jokers == 0 {
+		decompositions = win.Decompose(hand, nil, winTile, opts)
+	}
+	for _, d := range decompositions {
+		switch d.Form {
+		case win.StandardForm:
+			found[MeldWait(d.Melds[d.WinMeld], winTile)] = true
+		case win.SevenPairsForm:
+			found[PairWait] = true
+		case win.ThirteenOrphansForm:
+			if len(tingCards) == 1 {
+				found[PairWait] = true
+			}
+		}
+	}
+	var waits []Wait
+	for w := EdgeWait; w <= MultiWait; w++ {
+		if found[w] {
+			waits = append(waits, w)
+		}
+	}
+	return waits
+}
+
+// ClassifyWait 按 prefer 的顺序取第一个可能的听牌形状，prefer 是玩法规则认为从有利到不利的顺序
+// 不是胡牌或者所有可能的形状都不在 prefer 里时返回 false
+func ClassifyWait(handCards, showCards []card.ID, winTile card.ID, opts win.Options, prefer []Wait) (Wait, bool) {
+	waits := Waits(handCards, showCards, winTile, opts)
+	for _, p := range prefer {
+		for _, w := range waits {
+			if w == p {
+				return w, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// MeldWait 胡的牌 t 在拆牌后的 m 组里时的听牌形状
+func MeldWait(m win.Meld, t card.ID) Wait {
+	switch {
+	case m.Kind == win.Pair || m.Kind == win.Single:
+		return PairWait
+	case m.Kind != win.Chow:
+		return DualPungWait
+	case t == m.Tile+1:
+		return ClosedWait
+	case t == m.Tile && m.Tile.Rank() == 7, t == m.Tile+2 && m.Tile.Rank() == 1:
+		return EdgeWait
+	}
+	return OpenWait
+}
@@ -28,12 +28,15 @@ func IntInSlice(finder int, slice []int) bool {
 	return exists
 }
 
-// ShuffleSliceInt 打乱一个切片
+// ShuffleSliceInt 打乱一个切片，用当前时间作为种子，不影响全局的随机数
 func ShuffleSliceInt(src []int) []int {
-	dest := make([]int, len(src))
+	return ShuffleSliceIntWith(src, rand.New(rand.NewSource(time.Now().UTC().UnixNano())))
+}
 
-	rand.Seed(time.Now().UTC().UnixNano())
-	perm := rand.Perm(len(src))
+// ShuffleSliceIntWith 用指定的随机数打乱一个切片，种子一样时结果一样
+func ShuffleSliceIntWith(src []int, rng *rand.Rand) []int {
+	dest := make([]int, len(src))
+	perm := rng.Perm(len(src))
 
 	for i, v := range perm {
 		dest[v] = src[i]
@@ -0,0 +1,236 @@
+package win
+
+import (
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+)
+
+// MeldKind 拆牌后一组牌的类型
+type MeldKind int
+
+const (
+	Pair   MeldKind = iota // 将（雀头）
+	Chow                   // 顺子
+	Pung                   // 刻子
+	Kong                   // 杠
+	Single                 // 十三幺里除了将以外的单张
+)
+
+// Form 胡牌的牌型
+type Form int
+
+const (
+	StandardForm        Form = iota // 若干组顺子、刻子加一个将
+	SevenPairsForm                  // 七对
+	ThirteenOrphansForm             // 十三幺
+)
+
+// Meld 拆牌后的一组牌
+type Meld struct {
+	Kind      MeldKind
+	Tile      card.ID // 顺子是最小的一张
+	Concealed bool    // 在手里组成的，碰杠吃的牌中只有暗杠算
+	Declared  bool    // 碰杠吃的牌
+}
+
+// Tiles 这组牌包含的牌
+func (m Meld) Tiles() []card.ID {
+	switch m.Kind {
+	case Chow:
+		return []card.ID{m.Tile, m.Tile + 1, m.Tile + 2}
+	case Pung:
+		return []card.ID{m.Tile, m.Tile, m.Tile}
+	case Kong:
+		return []card.ID{m.Tile, m.Tile, m.Tile, m.Tile}
+	case Single:
+		return []card.ID{m.Tile}
+	}
+	return []card.ID{m.Tile, m.Tile}
+}
+
+// Contains 这组牌里有没有 t
+func (m Meld) Contains(t card.ID) bool {
+	if m.Kind == Chow {
+		return t >= m.Tile && t <= m.Tile+2
+	}
+	return t == m.Tile
+}
+
+// ShowCard 碰杠吃的一组牌，*game.ShowCard 实现了这个接口
+type ShowCard interface {
+	GetOpCode() int
+	GetTiles() []card.ID
+	IsShow() bool
+}
+
+// NewMeld 把碰杠吃的牌转换成拆牌后的一组牌
+func NewMeld(sc ShowCard) Meld {
+	tiles := sc.GetTiles()
+	m := Meld{Kind: Pung, Tile: tiles[0], Declared: true}
+	switch {
+	case sc.GetOpCode() == consts.CHI:
+		m.Kind = Chow
+		for _, t := range tiles {
+			if t < m.Tile {
+				m.Tile = t
+			}
+		}
+	case len(tiles) == 4:
+		m.Kind = Kong
+		m.Concealed = !sc.IsShow() || sc.GetOpCode() == consts.AN_GANG
+	}
+	return m
+}
+
+// Decomposition 胡牌的一种拆法
+type Decomposition struct {
+	Form    Form
+	Melds   []Meld // 先是手里的牌，一般牌型第一组是将，最后是碰杠吃的牌
+	WinMeld int    // 胡的那张牌所在的组的下标，没有指定胡的牌时是 -1
+}
+
+// Pair 将，没有将时返回 0
+func (d Decomposition) Pair() card.ID {
+	for _, m := range d.Melds {
+		if m.Kind == Pair {
+			return m.Tile
+		}
+	}
+	return 0
+}
+
+// Decompose 枚举胡牌的所有拆法
+// hand 是手牌，包括胡的那张牌 winTile；melds 是碰杠吃的牌，放在每种拆法的最后
+// 同一种拆法里胡的牌可以在不同的组时，每个位置算一种拆法，一样的组只算一次；winTile 为 0 时不区分
+// 七对和十三幺只在 opts 允许、没有碰杠吃并且手牌正好 14 张时才算，不处理赖子，不是胡牌时返回空
+func Decompose(hand []card.ID, melds []Meld, winTile card.ID, opts Options) []Decomposition {
+	c, ok := countTiles(hand)
+	if !ok || len(hand)%3 != 2 || winTile != 0 && c.Count(winTile) == 0 {
+		return nil
+	}
+	var ret []Decomposition
+	add := func(form Form, sets []Meld) {
+		all := make([]Meld, 0, len(sets)+len(melds))
+		all = append(append(all, sets...), melds...)
+		if winTile == 0 {
+			ret = append(ret, Decomposition{Form: form, Melds: all, WinMeld: -1})
+			return
+		}
+		seen := make(map[Meld]bool)
+		for i, s := range sets {
+			if !s.Contains(winTile) || seen[s] {
+				continue
+			}
+			seen[s] = true
+			ret = append(ret, Decomposition{Form: form, Melds: all, WinMeld: i})
+		}
+	}
+
+	if len(melds) == 0 && len(hand) == 14 {
+		if opts.ThirteenOrphans {
+			if sets, ok := thirteenOrphans(&c); ok {
+				add(ThirteenOrphansForm, sets)
+			}
+		}
+		if opts.SevenPairs {
+			if sets, ok := sevenPairs(&c); ok {
+				add(SevenPairsForm, sets)
+			}
+		}
+	}
+	for i := range c {
+		if c[i] < 2 {
+			continue
+		}
+		c[i] -= 2
+		for _, sets := range decomposeSets(&c) {
+			add(StandardForm, append([]Meld{{Kind: Pair, Tile: card.FromIndex(i), Concealed: true}}, sets...))
+		}
+		c[i] += 2
+	}
+	return ret
+}
+
+// countTiles 统计牌的张数，有花牌或者不存在的牌时返回 false
+func countTiles(tiles []card.ID) (card.Counts, bool) {
+	var c card.Counts
+	for _, t := range tiles {
+		if !c.Add(t) {
+			return c, false
+		}
+	}
+	return c, true
+}
+
+// decomposeSets 枚举把牌全部拆成顺子、刻子的所有拆法，拆不完时返回空
+// 每次处理最小的一张牌，它要么在刻子里，要么是顺子的第一张，所以不会重复
+func decomposeSets(c *card.Counts) [][]Meld {
+	i := 0
+	for i < len(c) && c[i] == 0 {
+		i++
+	}
+	if i == len(c) {
+		return [][]Meld{{}}
+	}
+	t := card.FromIndex(i)
+	var ret [][]Meld
+	if c[i] >= 3 {
+		c[i] -= 3
+		for _, rest := range decomposeSets(c) {
+			ret = append(ret, append([]Meld{{Kind: Pung, Tile: t, Concealed: true}}, rest...))
+		}
+		c[i] += 3
+	}
+	if card.IsSuitIndex(i) && t.Rank() <= 7 && c[i+1] > 0 && c[i+2] > 0 {
+		c[i]--
+		c[i+1]--
+		c[i+2]--
+		for _, rest := range decomposeSets(c) {
+			ret = append(ret, append([]Meld{{Kind: Chow, Tile: t, Concealed: true}}, rest...))
+		}
+		c[i]++
+		c[i+1]++
+		c[i+2]++
+	}
+	return ret
+}
+
+// sevenPairs 七对，和 CanWin 一样四张一样的牌算两对
+func sevenPairs(c *card.Counts) ([]Meld, bool) {
+	var sets []Meld
+	for i, n := range c {
+		if n%2 != 0 {
+			return nil, false
+		}
+		for j := 0; j < int(n)/2; j++ {
+			sets = append(sets, Meld{Kind: Pair, Tile: card.FromIndex(i), Concealed: true})
+		}
+	}
+	return sets, len(sets) == 7
+}
+
+// thirteenOrphans 十三幺，将在第一组，其他十二种么九牌是单张
+func thirteenOrphans(c *card.Counts) ([]Meld, bool) {
+	sets := []Meld{{Kind: Pair, Concealed: true}}
+	for i, n := range c {
+		if n == 0 {
+			continue
+		}
+		id := card.FromIndex(i)
+		if !id.IsHonor() && id.Rank() != 1 && id.Rank() != 9 {
+			return nil, false
+		}
+		switch n {
+		case 1:
+			sets = append(sets, Meld{Kind: Single, Tile: id, Concealed: true})
+		case 2:
+			if sets[0].Tile != 0 {
+				return nil, false
+			}
+			sets[0].Tile = id
+		default:
+			return nil, false
+		}
+	}
+	return sets, sets[0].Tile != 0 && len(sets) == 13
+}
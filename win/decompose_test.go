@@ -0,0 +1,87 @@
+package win
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+	"github.com/mikodream/mahjong/consts"
+)
+
+type testShowCard struct {
+	op    int
+	tiles []card.ID
+	show  bool
+}
+
+func (s testShowCard) GetOpCode() int      { return s.op }
+func (s testShowCard) GetTiles() []card.ID { return s.tiles }
+func (s testShowCard) IsShow() bool        { return s.show }
+
+func TestDecompose(t *testing.T) {
+	// 111222333 万可以拆成三个刻子或者三个顺子，胡 2 万时可以在刻子或者任意一个顺子里
+	hand := []card.ID{1, 1, 1, 2, 2, 2, 3, 3, 3, 5, 6, 7, 31, 31}
+	ds := Decompose(hand, nil, 2, DefaultOptions)
+	if len(ds) != 2 {
+		t.Fatalf("拆法数量错误: %+v", ds)
+	}
+	for _, d := range ds {
+		if d.Form != StandardForm || d.Pair() != 31 || d.Melds[0].Kind != Pair || !d.Melds[d.WinMeld].Contains(2) {
+			t.Errorf("拆法错误: %+v", d)
+		}
+	}
+	if ds := Decompose(hand, nil, 0, DefaultOptions); len(ds) != 2 || ds[0].WinMeld != -1 {
+		t.Errorf("不指定胡的牌时每种拆法只算一次: %+v", ds)
+	}
+
+	// 七对也可以拆成一般牌型
+	hand = []card.ID{1, 1, 2, 2, 3, 3, 5, 5, 6, 6, 7, 7, 31, 31}
+	forms := make(map[Form]int)
+	for _, d := range Decompose(hand, nil, 31, DefaultOptions) {
+		forms[d.Form]++
+	}
+	if forms[SevenPairsForm] != 1 || forms[StandardForm] != 1 {
+		t.Errorf("七对的拆法错误: %v", forms)
+	}
+	if ds := Decompose(hand, nil, 31, Options{}); len(ds) != 1 || ds[0].Form != StandardForm {
+		t.Errorf("不能胡七对时只有一般牌型: %+v", ds)
+	}
+
+	hand = []card.ID{1, 9, 11, 19, 21, 29, 31, 32, 33, 34, 41, 42, 43, 43}
+	ds = Decompose(hand, nil, 1, DefaultOptions)
+	if len(ds) != 1 || ds[0].Form != ThirteenOrphansForm || ds[0].Pair() != 43 || len(ds[0].Melds) != 13 ||
+		ds[0].Melds[ds[0].WinMeld].Kind != Single {
+		t.Errorf("十三幺的拆法错误: %+v", ds)
+	}
+
+	// 碰杠吃的牌在最后，胡的牌只在手里的组
+	melds := []Meld{
+		NewMeld(testShowCard{op: consts.CHI, tiles: []card.ID{13, 11, 12}, show: true}),
+		NewMeld(testShowCard{op: consts.GANG, tiles: []card.ID{5, 5, 5, 5}}),
+	}
+	if melds[0] != (Meld{Kind: Chow, Tile: 11, Declared: true}) || melds[1] != (Meld{Kind: Kong, Tile: 5, Concealed: true, Declared: true}) {
+		t.Errorf("明牌转换错误: %+v", melds)
+	}
+	ds = Decompose([]card.ID{11, 12, 13, 21, 21, 21, 31, 31}, melds, 21, DefaultOptions)
+	if len(ds) != 1 || len(ds[0].Melds) != 5 || ds[0].WinMeld != 2 || ds[0].Melds[4] != melds[1] {
+		t.Errorf("有明牌的拆法错误: %+v", ds)
+	}
+
+	if ds := Decompose([]card.ID{1, 2, 4, 31, 31}, nil, 4, DefaultOptions); ds != nil {
+		t.Errorf("不是胡牌时应该没有拆法: %+v", ds)
+	}
+}
+
+// 有拆法和 CanWin 的结果一致
+func TestDecomposeCanWin(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		hand := make([]card.ID, 14)
+		for j := range hand {
+			hand[j] = card.ID(rng.Intn(9) + 1)
+		}
+		if got, want := len(Decompose(hand, nil, hand[0], DefaultOptions)) > 0, CanWin(hand, nil); got != want {
+			t.Errorf("%v 拆法 %v, CanWin %v", hand, got, want)
+		}
+	}
+}
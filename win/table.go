@@ -0,0 +1,174 @@
+package win
+
+import (
+	"sync"
+
+	"github.com/mikodream/mahjong/card"
+)
+
+// 查表判断胡牌：每门数牌按 1 到 9 的张数编码成一个五进制的数，
+// 预先算出所有能拆成若干顺子、刻子（最多一个将）的编码，判断时每门花色查一次表
+const (
+	tableMaxSets  = 5                                 // 每门花色最多几组，台湾麻将是五组加一对
+	tableMaxTiles = tableMaxSets*3 + 2                // 超过这个张数时用 CanWinWith 判断
+	suitKeys      = 5 * 5 * 5 * 5 * 5 * 5 * 5 * 5 * 5 // 每种牌 0 到 4 张，共 5^9 种编码
+)
+
+var (
+	tableOnce sync.Once
+	suitTable []uint64 // 按编码的位图，能拆成若干组加最多一个将的编码为 1
+)
+
+// CanWinFast 查表判断当前牌型是否是胡牌牌型，和 CanWin 一样
+func CanWinFast(handTiles, showTiles []card.ID) bool {
+	return CanWinFastWith(handTiles, showTiles, DefaultOptions)
+}
+
+// CanWinFastWith 按指定的规则查表判断胡牌，不分配内存
+// 第一次调用时生成表；有赖子、手牌超过 17 张或者同一种牌超过 4 张时复制、排序之后判断
+func CanWinFastWith(handTiles, showTiles []card.ID, opts Options) bool {
+	if len(handTiles)%3 != 2 {
+		return false
+	}
+	if len(handTiles) > tableMaxTiles {
+		return canWinSorted(handTiles, opts)
+	}
+	var c card.Counts
+	for _, t := range handTiles {
+		if opts.Wildcards.Contains(t) {
+			return canWinSorted(handTiles, opts)
+		}
+		if !c.Add(t) {
+			return false
+		}
+	}
+	return CanWinCounts(&c, opts)
+}
+
+// CanWinCounts 按指定的规则用每种牌的张数判断胡牌，用于听牌时逐个试摸到每一种牌
+// 查表时不分配内存；有赖子、超过 17 张或者同一种牌超过 4 张时转成牌再判断
+func CanWinCounts(c *card.Counts, opts Options) bool {
+	n := c.Len()
+	if n%3 != 2 {
+		return false
+	}
+	if n > tableMaxTiles {
+		return canWinSorted(c.Tiles(), opts)
+	}
+	for _, t := range opts.Wildcards {
+		if c.Count(t) > 0 {
+			return canWinSorted(c.Tiles(), opts)
+		}
+	}
+	for _, v := range c {
+		if v > 4 {
+			return canWinSorted(c.Tiles(), opts)
+		}
+	}
+	tableOnce.Do(buildSuitTable)
+
+	if n == 14 && (opts.ThirteenOrphans && countsThirteenOrphans(c) || opts.SevenPairs && countsSevenPairs(c)) {
+		return true
+	}
+
+	// 每门数牌的下标是连续的九个
+	pairs := 0
+	for suit := 0; suit < card.SuitKinds; suit += 9 {
+		key, sum := 0, 0
+		for r := 8; r >= 0; r-- {
+			key = key*5 + int(c[suit+r])
+			sum += int(c[suit+r])
+		}
+		switch {
+		case sum%3 == 1 || suitTable[key/64]&(1<<(key%64)) == 0:
+			return false
+		case sum%3 == 2:
+			pairs++
+		}
+	}
+	for _, n := range c[card.SuitKinds:] {
+		switch n {
+		case 1, 4:
+			return false
+		case 2:
+			pairs++
+		}
+	}
+	return pairs == 1
+}
+
+// countsSevenPairs 14 张牌是不是七对，四张一样的牌算两对
+func countsSevenPairs(c *card.Counts) bool {
+	for _, n := range c {
+		if n%2 != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// countsThirteenOrphans 14 张牌是不是十三幺
+func countsThirteenOrphans(c *card.Counts) bool {
+	kinds := 0
+	c.Each(func(id card.ID, n int) bool {
+		if n > 2 || !id.IsHonor() && id.Rank() != 1 && id.Rank() != 9 {
+			kinds = 0
+			return false
+		}
+		kinds++
+		return true
+	})
+	return kinds == 13
+}
+
+// buildSuitTable 从空牌开始每次加一组顺子或刻子，再给每种拆法加一个将
+func buildSuitTable() {
+	suitTable = make([]uint64, suitKeys/64+1)
+	var counts [9]int
+	var add func(sets, first int)
+	add = func(sets, first int) {
+		mark(&counts)
+		for r := 0; r < 9; r++ {
+			if counts[r] <= 2 {
+				counts[r] += 2
+				mark(&counts)
+				counts[r] -= 2
+			}
+		}
+		if sets == tableMaxSets {
+			return
+		}
+		// 0 到 8 是刻子，9 到 15 是顺子，只加不小于 first 的组，避免重复
+		for m := first; m < 16; m++ {
+			if m < 9 {
+				if counts[m] > 1 {
+					continue
+				}
+				counts[m] += 3
+				add(sets+1, m)
+				counts[m] -= 3
+				continue
+			}
+			r := m - 9
+			if counts[r] == 4 || counts[r+1] == 4 || counts[r+2] == 4 {
+				continue
+			}
+			counts[r]++
+			counts[r+1]++
+			counts[r+2]++
+			add(sets+1, m)
+			counts[r]--
+			counts[r+1]--
+			counts[r+2]--
+		}
+	}
+	add(0, 0)
+}
+
+func mark(counts *[9]int) {
+	key := 0
+	for r := 8; r >= 0; r-- {
+		key = key*5 + counts[r]
+	}
+	suitTable[key/64] |= 1 << (key % 64)
+}
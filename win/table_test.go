@@ -0,0 +1,98 @@
+package win
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+)
+
+// 一门花色所有可能的手牌查表都和排序拆牌的结果一致
+func TestCanWinFastSuit(t *testing.T) {
+	var counts [9]int
+	hand := make([]card.ID, 0, 14)
+	checked := 0
+	var walk func(r, sum int)
+	walk = func(r, sum int) {
+		if r == len(counts) {
+			if sum%3 != 2 {
+				return
+			}
+			hand = hand[:0]
+			for i, n := range counts {
+				for j := 0; j < n; j++ {
+					hand = append(hand, card.ID(i+1))
+				}
+			}
+			checked++
+			if got, want := CanWinFast(hand, nil), canWinSorted(hand, DefaultOptions); got != want {
+				t.Fatalf("CanWinFast(%v) = %v, 排序拆牌是 %v", hand, got, want)
+			}
+			return
+		}
+		for n := 0; n <= 4 && sum+n <= 14; n++ {
+			counts[r] = n
+			walk(r+1, sum+n)
+		}
+		counts[r] = 0
+	}
+	walk(0, 0)
+	if checked == 0 {
+		t.Errorf("没有检查任何手牌")
+	}
+}
+
+// 随机的多门花色和字牌、十六张麻将的手牌查表都和排序拆牌的结果一致
+func TestCanWinFastRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tiles := make([]card.ID, len(card.MahjongCards136))
+	copy(tiles, card.MahjongCards136)
+	for i := 0; i < 20000; i++ {
+		size := []int{2, 5, 8, 11, 14, 17}[r.Intn(6)]
+		var hand []card.ID
+		if i%2 == 0 {
+			// 随机的牌几乎都胡不了，一半的手牌用胡牌加一张换掉的牌
+			hand = winningHand(r, size)
+		} else {
+			r.Shuffle(len(tiles), func(i, j int) { tiles[i], tiles[j] = tiles[j], tiles[i] })
+			hand = append([]card.ID{}, tiles[:size]...)
+		}
+		if i%4 == 2 {
+			hand[r.Intn(size)] = card.AllTiles[r.Intn(len(card.AllTiles))]
+		}
+		for _, opts := range []Options{DefaultOptions, {}} {
+			if got, want := CanWinFastWith(hand, nil, opts), canWinSorted(hand, opts); got != want {
+				t.Fatalf("CanWinFastWith(%v, %+v) = %v, 排序拆牌是 %v", hand, opts, got, want)
+			}
+		}
+	}
+}
+
+// winningHand 随机组成 size 张的胡牌，同一种牌可能超过 4 张
+func winningHand(r *rand.Rand, size int) []card.ID {
+	pair := card.AllTiles[r.Intn(len(card.AllTiles))]
+	hand := []card.ID{pair, pair}
+	for len(hand) < size {
+		t := card.AllTiles[r.Intn(len(card.AllTiles))]
+		if t.IsSuit() && t.Rank() <= 7 && r.Intn(2) == 0 {
+			hand = append(hand, t, t+1, t+2)
+		} else {
+			hand = append(hand, t, t, t)
+		}
+	}
+	r.Shuffle(len(hand), func(i, j int) { hand[i], hand[j] = hand[j], hand[i] })
+	return hand
+}
+
+// CanWinWith 和听牌时用的 CanWinCounts 没有赖子时不分配内存
+func TestCanWinNoAlloc(t *testing.T) {
+	hand := []card.ID{6, 7, 9, 9, 12, 12, 13, 14, 15, 15, 17, 26, 27, 28}
+	counts := card.NewCounts(hand)
+	allocs := testing.AllocsPerRun(100, func() {
+		CanWinWith(hand, nil, DefaultOptions)
+		CanWinCounts(&counts, DefaultOptions)
+	})
+	if allocs != 0 {
+		t.Errorf("判断胡牌不应该分配内存, 实际 %v 次", allocs)
+	}
+}
@@ -0,0 +1,151 @@
+package win
+
+import (
+	"github.com/mikodream/mahjong/card"
+)
+
+// impossible 用赖子也凑不出来时需要的赖子数
+const impossible = 1 << 10
+
+// suitCounts 按花色分组的张数，下标是 card.ID 的十位（万、条、饼、风、箭）和个位
+type suitCounts [5][10]int
+
+// canWinWithWildcards 手里有赖子时判断是否胡牌
+// tiles 是除赖子以外的牌，jokers 是赖子的张数
+// 不枚举赖子当作哪张牌，而是分别计算每种牌型最少需要几个赖子，所以和赖子的数量无关
+func canWinWithWildcards(tiles []card.ID, jokers int, opts Options) bool {
+	total := len(tiles) + jokers
+	if total%3 != 2 {
+		return false
+	}
+	var counts suitCounts
+	for _, t := range tiles {
+		if t.IsBonus() || t <= 0 {
+			return false
+		}
+		counts[t/10][t%10]++
+	}
+	if total == 14 && opts.ThirteenOrphans && thirteenOrphansNeed(counts) <= jokers {
+		return true
+	}
+	if total == 14 && opts.SevenPairs && sevenPairsNeed(counts) <= jokers {
+		return true
+	}
+	return standardNeed(counts) <= jokers
+}
+
+// standardNeed 标准胡牌 (x * (ABC/AAA) + DD) 最少需要的赖子数
+// 每门花色单独计算组成顺子、刻子需要的赖子数，再选一门做将
+func standardNeed(counts suitCounts) int {
+	var melds [5]int
+	sum := 0
+	for s := range counts {
+		melds[s] = meldNeed(&counts[s], s >= 3)
+		sum += melds[s]
+	}
+	// 两个赖子做将
+	best := sum + 2
+	for s := range counts {
+		if need := sum - melds[s] + pairNeed(&counts[s], s >= 3); need < best {
+			best = need
+		}
+	}
+	return best
+}
+
+// meldNeed 一门花色全部组成顺子、刻子最少需要的赖子数，字牌只能组成刻子
+// 每次处理最小的一张牌，它要么在刻子里，要么是顺子的第一张
+func meldNeed(c *[10]int, honor bool) int {
+	i := 1
+	for i <= 9 && c[i] == 0 {
+		i++
+	}
+	if i > 9 {
+		return 0
+	}
+
+	// 刻子
+	take := c[i]
+	if take > 3 {
+		take = 3
+	}
+	c[i] -= take
+	best := 3 - take + meldNeed(c, honor)
+	c[i] += take
+
+	// 顺子，缺的牌用赖子补，8 和 9 开头时当作 7 8 9
+	if !honor && i <= 8 {
+		need := 0
+		c[i]--
+		used := make([]int, 0, 2)
+		for _, j := range []int{i + 1, i + 2} {
+			if j <= 9 && c[j] > 0 {
+				c[j]--
+				used = append(used, j)
+			} else {
+				need++
+			}
+		}
+		if n := need + meldNeed(c, honor); n < best {
+			best = n
+		}
+		for _, j := range used {
+			c[j]++
+		}
+		c[i]++
+	}
+	return best
+}
+
+// pairNeed 一门花色做将并且其余的牌组成顺子、刻子最少需要的赖子数
+func pairNeed(c *[10]int, honor bool) int {
+	best := impossible
+	for i := 1; i <= 9; i++ {
+		if c[i] == 0 {
+			continue
+		}
+		take := c[i]
+		if take > 2 {
+			take = 2
+		}
+		c[i] -= take
+		if need := 2 - take + meldNeed(c, honor); need < best {
+			best = need
+		}
+		c[i] += take
+	}
+	return best
+}
+
+// sevenPairsNeed 七对最少需要的赖子数：每张单着的牌都要一个赖子配对
+func sevenPairsNeed(counts suitCounts) int {
+	need := 0
+	for s := range counts {
+		for _, n := range counts[s] {
+			need += n % 2
+		}
+	}
+	return need
+}
+
+// thirteenOrphansNeed 十三幺最少需要的赖子数：缺的幺九牌和没有的将都用赖子补
+func thirteenOrphansNeed(counts suitCounts) int {
+	kinds, hasEye := 0, false
+	for s := range counts {
+		for r, n := range counts[s] {
+			if n == 0 {
+				continue
+			}
+			if !card.IsTerminal(card.ID(s*10+r)) || n > 2 || (n == 2 && hasEye) {
+				return impossible
+			}
+			kinds++
+			hasEye = hasEye || n == 2
+		}
+	}
+	need := 13 - kinds
+	if !hasEye {
+		need++
+	}
+	return need
+}
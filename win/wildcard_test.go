@@ -0,0 +1,92 @@
+package win
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/mikodream/mahjong/card"
+)
+
+func TestCanWinWithWildcards(t *testing.T) {
+	opts := DefaultOptions
+	opts.Wildcards = card.Wildcards{card.MAHJONG_RED}
+	cases := []struct {
+		hand []card.ID
+		win  bool
+	}{
+		{[]card.ID{1, 2, 42, 5, 5}, true},                                       // 赖子做顺子
+		{[]card.ID{1, 3, 42, 5, 5}, true},                                       // 赖子做坎张
+		{[]card.ID{8, 9, 42, 5, 5}, true},                                       // 赖子做边张 7
+		{[]card.ID{1, 2, 3, 5, 42}, true},                                       // 赖子做将
+		{[]card.ID{31, 31, 42, 5, 5}, true},                                     // 赖子做刻子
+		{[]card.ID{1, 5, 9, 42, 42}, false},                                     // 三张散牌两个赖子不够
+		{[]card.ID{1, 5, 42, 42, 42}, true},                                     // 三个赖子
+		{[]card.ID{42, 42}, true},                                               // 两个赖子做将
+		{[]card.ID{31, 32, 42, 5, 5}, false},                                    // 字牌不能做顺子
+		{[]card.ID{1, 1, 3, 3, 5, 5, 7, 7, 11, 11, 13, 13, 15, 42}, true},       // 七对
+		{[]card.ID{1, 9, 11, 19, 21, 29, 31, 32, 33, 34, 41, 43, 42, 42}, true}, // 十三幺，赖子当中和将
+		{[]card.ID{1, 9, 11, 19, 21, 29, 31, 32, 33, 34, 41, 5, 42, 42}, false},
+	}
+	for _, c := range cases {
+		if win := CanWinWith(c.hand, nil, opts); win != c.win {
+			t.Errorf("CanWinWith(%v) 期望 %v, 实际 %v", c.hand, c.win, win)
+		}
+	}
+}
+
+// TestWildcardsAgainstBruteForce 和把每个赖子换成所有可能的牌的结果比较
+func TestWildcardsAgainstBruteForce(t *testing.T) {
+	opts := DefaultOptions
+	opts.Wildcards = card.Wildcards{card.MAHJONG_RED}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		jokers := 1 + r.Intn(2)
+		size := []int{5, 8, 11, 14}[r.Intn(4)]
+		hand := make([]card.ID, 0, size)
+		for len(hand) < size-jokers {
+			if t := card.AllTiles[r.Intn(len(card.AllTiles))]; t != card.MAHJONG_RED {
+				hand = append(hand, t)
+			}
+		}
+		// 让一部分手牌接近胡牌，不然几乎都胡不了
+		if i%2 == 0 && len(hand) >= 3 {
+			hand[1], hand[2] = hand[0], hand[0]
+		}
+		for j := 0; j < jokers; j++ {
+			hand = append(hand, card.MAHJONG_RED)
+		}
+		if got, want := CanWinWith(hand, nil, opts), bruteForceWin(hand, jokers); got != want {
+			t.Fatalf("CanWinWith(%v) = %v, 枚举的结果是 %v", hand, got, want)
+		}
+	}
+}
+
+// bruteForceWin 把最后 jokers 张赖子依次换成所有牌，判断能不能胡
+func bruteForceWin(hand []card.ID, jokers int) bool {
+	if jokers == 0 {
+		return CanWinWith(hand, nil, DefaultOptions)
+	}
+	tmp := make([]card.ID, len(hand))
+	copy(tmp, hand)
+	for _, t := range card.AllTiles {
+		tmp[len(tmp)-jokers] = t
+		if bruteForceWin(tmp, jokers-1) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGetTingTilesWithWildcards(t *testing.T) {
+	opts := DefaultOptions
+	opts.Wildcards = card.Wildcards{card.MAHJONG_RED}
+	// 一个赖子单吊，什么牌都能胡
+	if ting := GetTingTilesWith([]card.ID{1, 2, 3, 42}, nil, opts); len(ting) != len(card.AllTiles) {
+		t.Errorf("单吊赖子应该听所有牌, 实际 %v", ting)
+	}
+	// 没有赖子时也可以摸到赖子胡
+	ting := GetTingTilesWith([]card.ID{1, 2, 3, 5}, nil, opts)
+	if !card.IDInSlice(card.MAHJONG_RED, ting) || !card.IDInSlice(5, ting) || len(ting) != 2 {
+		t.Errorf("听牌错误: %v", ting)
+	}
+}
@@ -6,9 +6,37 @@ import (
 	"github.com/mikodream/mahjong/card"
 )
 
+// Options 胡牌规则，控制允许哪些特殊牌型
+type Options struct {
+	SevenPairs      bool           // 七对
+	ThirteenOrphans bool           // 十三幺
+	Wildcards       card.Wildcards // 赖子，可以当作任意一张牌
+}
+
+// DefaultOptions 默认规则，七对和十三幺都可以胡
+var DefaultOptions = Options{SevenPairs: true, ThirteenOrphans: true}
+
 // CanWin 判断当前牌型是否是胡牌牌型
-// 支持：标准胡牌(3n+2), 七对, 十三幺
+// 支持：标准胡牌(3n+2，手牌可以是任意 3n+2 张，比如台湾麻将的 17 张), 七对, 十三幺
 func CanWin(handTiles, showTiles []card.ID) bool {
+	return CanWinWith(handTiles, showTiles, DefaultOptions)
+}
+
+// CanWinWith 按指定的规则判断当前牌型是否是胡牌牌型
+// 七对和十三幺只在手牌正好 14 张时判断，一般情况下查表判断，不分配内存，见 CanWinFastWith
+func CanWinWith(handTiles, showTiles []card.ID, opts Options) bool {
+	return CanWinFastWith(handTiles, showTiles, opts)
+}
+
+// canWinSorted 复制、排序之后逐个拆将牌判断胡牌，用于查表处理不了的手牌：有赖子、超过 17 张、同一种牌超过 4 张
+func canWinSorted(handTiles []card.ID, opts Options) bool {
+	if len(handTiles)%3 != 2 {
+		return false
+	}
+	if tiles, jokers := opts.Wildcards.Split(handTiles); jokers > 0 {
+		return canWinWithWildcards(tiles, jokers, opts)
+	}
+
 	// 复制并排序，以免修改原切片
 	sortedTiles := make([]card.ID, len(handTiles))
 	copy(sortedTiles, handTiles)
@@ -17,13 +45,13 @@ func CanWin(handTiles, showTiles []card.ID) bool {
 	// 1. 判断十三幺 (Thirteen Orphans)
 	// 十三幺必须是门清（没有碰/杠/吃，即 showTiles 为空）
 	// 但考虑到有些游戏实现可能只传 handTiles，这里只检查手牌数量是否足够
-	if len(sortedTiles) == 14 && IsThirteenOrphans(sortedTiles) {
+	if opts.ThirteenOrphans && len(sortedTiles) == 14 && IsThirteenOrphans(sortedTiles) {
 		return true
 	}
 
 	// 2. 判断七对 (Seven Pairs)
 	// 七对必须是14张牌
-	if len(sortedTiles) == 14 {
+	if opts.SevenPairs && len(sortedTiles) == 14 {
 		pairs := FindPairPos(sortedTiles)
 		if len(pairs) == 7 {
 			return true
@@ -59,22 +87,25 @@ func CanWin(handTiles, showTiles []card.ID) bool {
 // 输入：handTiles (当前手里的牌，通常是 1, 4, 7, 10, 13 张)
 // 输出：所有能胡的牌 ID 列表
 func GetTingTiles(handTiles, showTiles []card.ID) []card.ID {
+	return GetTingTilesWith(handTiles, showTiles, DefaultOptions)
+}
+
+// GetTingTilesWith 按指定的规则获取听牌列表
+func GetTingTilesWith(handTiles, showTiles []card.ID, opts Options) []card.ID {
 	tingList := make([]card.ID, 0)
+	counts := card.NewCounts(handTiles)
+	if counts.Len() != len(handTiles) {
+		// 手里有花牌，胡不了
+		return tingList
+	}
 
-	// 遍历麻将所有可能的 34 种牌
+	// 遍历麻将所有可能的 34 种牌，模拟摸到这一张牌，检查是否胡了
 	for _, t := range card.AllTiles {
-		tID := card.ID(t)
-		// 模拟摸这一张牌：
-		// 1. 复制当前手牌 (避免修改原切片)
-		// 2. 加上这张牌 t
-		tempHand := make([]card.ID, len(handTiles), len(handTiles)+1)
-		copy(tempHand, handTiles)
-		tempHand = append(tempHand, tID)
-
-		// 检查加上这张牌后是否胡了
-		if CanWin(tempHand, showTiles) {
-			tingList = append(tingList, tID)
+		counts.Add(t)
+		if CanWinCounts(&counts, opts) {
+			tingList = append(tingList, t)
 		}
+		counts.Remove(t)
 	}
 
 	return tingList
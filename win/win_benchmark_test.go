@@ -12,3 +12,43 @@ func BenchmarkWin(b *testing.B) {
 		CanWin(handCards, nil)
 	}
 }
+
+func BenchmarkWinWithWildcards(b *testing.B) {
+	opts := DefaultOptions
+	opts.Wildcards = card.Wildcards{card.MAHJONG_RED}
+	for i := 0; i < b.N; i++ {
+		handCards := []card.ID{6, 7, 9, 12, 13, 14, 15, 17, 26, 42, 42, 42, 42, 42}
+		CanWinWith(handCards, nil, opts)
+	}
+}
+
+func BenchmarkWinFast(b *testing.B) {
+	handCards := []card.ID{6, 7, 9, 9, 12, 12, 13, 14, 15, 15, 17, 26, 27, 28}
+	CanWinFast(handCards, nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CanWinFast(handCards, nil)
+	}
+}
+
+// 听牌时每种牌都要判断一次胡牌
+func BenchmarkTingTiles(b *testing.B) {
+	handCards := []card.ID{1, 1, 1, 2, 3, 4, 5, 6, 7, 8, 9, 9, 9}
+	for i := 0; i < b.N; i++ {
+		GetTingTiles(handCards, nil)
+	}
+}
+
+func BenchmarkTingTilesFast(b *testing.B) {
+	handCards := []card.ID{1, 1, 1, 2, 3, 4, 5, 6, 7, 8, 9, 9, 9}
+	tempHand := make([]card.ID, len(handCards)+1)
+	copy(tempHand, handCards)
+	CanWinFast(tempHand, nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, t := range card.AllTiles {
+			tempHand[len(handCards)] = t
+			CanWinFast(tempHand, nil)
+		}
+	}
+}
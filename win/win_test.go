@@ -233,3 +233,42 @@ func TestFailCases(t *testing.T) {
 		t.Error("杂乱牌不应胡")
 	}
 }
+
+// TestCanWinWithOptions 测试关闭七对、十三幺
+func TestCanWinWithOptions(t *testing.T) {
+	sevenPairs := []card.ID{1, 1, 3, 3, 5, 5, 7, 7, 9, 9, 11, 11, 13, 13}
+	thirteenOrphans := []card.ID{
+		card.MAHJONG_CRAK1, card.MAHJONG_CRAK1, card.MAHJONG_CRAK9,
+		card.MAHJONG_DOT1, card.MAHJONG_DOT9,
+		card.MAHJONG_BAM1, card.MAHJONG_BAM9,
+		card.MAHJONG_EAST, card.MAHJONG_SOUTH, card.MAHJONG_WEST, card.MAHJONG_NORTH,
+		card.MAHJONG_RED, card.MAHJONG_GREE, card.MAHJONG_WHITE,
+	}
+	if CanWinWith(sevenPairs, nil, Options{ThirteenOrphans: true}) {
+		t.Error("不允许七对时不应该胡")
+	}
+	if CanWinWith(thirteenOrphans, nil, Options{SevenPairs: true}) {
+		t.Error("不允许十三幺时不应该胡")
+	}
+	if !CanWinWith([]card.ID{1, 1, 1, 2, 3, 4, 9, 9}, nil, Options{}) {
+		t.Error("标准胡牌不受规则影响")
+	}
+}
+
+// TestCanWinSixteenTiles 测试台湾麻将 17 张的胡牌和听牌
+func TestCanWinSixteenTiles(t *testing.T) {
+	hand := []card.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 11, 11, 11, 21, 22, 23, 29, 29}
+	if !CanWinWith(hand, nil, Options{}) {
+		t.Error("五组加一对应该胡")
+	}
+	if CanWinWith(hand[:16], nil, Options{}) {
+		t.Error("16 张不是 3n+2，不应该胡")
+	}
+	pairs := []card.ID{1, 1, 3, 3, 5, 5, 7, 7, 9, 9, 11, 11, 13, 13, 15, 15, 15}
+	if CanWinWith(pairs, nil, DefaultOptions) {
+		t.Error("17 张不能按七对胡")
+	}
+	if ting := GetTingTilesWith(hand[:16], nil, Options{}); !reflect.DeepEqual(ting, []card.ID{29}) {
+		t.Errorf("听牌错误: %v", ting)
+	}
+}